@@ -0,0 +1,13 @@
+package adaptiveLimit
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestAdaptiveLimit(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "AdaptiveLimit Suite")
+}
@@ -0,0 +1,50 @@
+package adaptiveLimit
+
+import "time"
+
+const (
+	defaultAIMDIncrease     = 1
+	defaultAIMDBackoffRatio = 0.9
+)
+
+type AIMDOpts struct {
+	// Increase is added to the limit after each successful call that used at least half of the limit. Defaults to 1
+	Increase float64
+
+	// BackoffRatio multiplies the limit after each dropped call, from 0 to 1. Defaults to 0.9
+	BackoffRatio float64
+
+	// Timeout, if greater than 0, treats calls slower than this as dropped even if they succeeded
+	Timeout time.Duration
+}
+
+// AIMD is the additive-increase, multiplicative-decrease algorithm TCP uses for congestion control.
+// The limit grows slowly while calls succeed and shrinks quickly when they are dropped. Use NewAIMD to create one
+type AIMD struct {
+	opts AIMDOpts
+}
+
+// NewAIMD creates a new AIMD algorithm
+func NewAIMD(opts AIMDOpts) *AIMD {
+	if opts.Increase <= 0 {
+		opts.Increase = defaultAIMDIncrease
+	}
+	if opts.BackoffRatio <= 0 || opts.BackoffRatio >= 1 {
+		opts.BackoffRatio = defaultAIMDBackoffRatio
+	}
+	return &AIMD{
+		opts: opts,
+	}
+}
+
+// Update satisfies Algorithm
+func (a *AIMD) Update(limit float64, sample Sample) float64 {
+	if sample.Dropped || (a.opts.Timeout > 0 && sample.Latency > a.opts.Timeout) {
+		return limit * a.opts.BackoffRatio
+	}
+	// only grow when the limit is actually being used, otherwise an idle client would grow it without bound
+	if float64(sample.InFlight)*2 >= limit {
+		return limit + a.opts.Increase
+	}
+	return limit
+}
@@ -0,0 +1,44 @@
+package adaptiveLimit
+
+import (
+	. "github.com/onsi/gomega"
+	"testing"
+	"time"
+)
+
+func TestAIMD_Update(t *testing.T) {
+	cases := map[string]struct {
+		opts     AIMDOpts
+		limit    float64
+		sample   Sample
+		expected float64
+	}{
+		"grows when busy": {
+			limit:    10,
+			sample:   Sample{InFlight: 5, Latency: time.Millisecond},
+			expected: 11,
+		},
+		"does not grow when idle": {
+			limit:    10,
+			sample:   Sample{InFlight: 1, Latency: time.Millisecond},
+			expected: 10,
+		},
+		"backs off when dropped": {
+			limit:    10,
+			sample:   Sample{InFlight: 5, Dropped: true},
+			expected: 9,
+		},
+		"backs off when too slow": {
+			opts:     AIMDOpts{Timeout: time.Second, BackoffRatio: 0.5},
+			limit:    10,
+			sample:   Sample{InFlight: 5, Latency: 2 * time.Second},
+			expected: 5,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(NewAIMD(dt.opts).Update(dt.limit, dt.sample)).Should(BeNumerically("~", dt.expected, 0.0001))
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package adaptiveLimit
+
+import "time"
+
+// Sample is what the Limiter observed about a single call
+type Sample struct {
+	// Latency is how long the call took
+	Latency time.Duration
+
+	// InFlight is the number of calls in flight when this call started, including this call
+	InFlight int
+
+	// Dropped is true if the call returned a tripping error, meaning the backend is overloaded
+	Dropped bool
+}
+
+// Algorithm learns the concurrency limit from the calls made through a Limiter.
+// The Limiter never calls Update concurrently, so algorithms do not need to be thread-safe
+// but should not be shared between limiters.
+type Algorithm interface {
+	// Update returns the new limit, given the current limit and a sample of a call that just completed.
+	// The Limiter keeps the result within its MinLimit and MaxLimit
+	Update(limit float64, sample Sample) (newLimit float64)
+}
@@ -0,0 +1,70 @@
+package adaptiveLimit
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	defaultGradientSmoothing     = 0.2
+	defaultGradientLongSmoothing = 0.05
+	minimumGradient              = 0.5
+)
+
+type GradientOpts struct {
+	// Smoothing is how quickly the limit moves toward each newly computed limit, from 0 to 1. Defaults to 0.2
+	Smoothing float64
+
+	// LongSmoothing is how quickly the long-term latency average follows each call, from 0 to 1. Smaller values
+	// remember the healthy latency longer. Defaults to 0.05
+	LongSmoothing float64
+}
+
+// Gradient compares each call's latency with a long-term average latency. The ratio, or gradient, shrinks the limit
+// when latency rises above the average and lets it grow, by roughly the square root of the limit, when latency is at
+// or below the average. Dropped calls are treated as the steepest gradient and never let the limit grow, so it always
+// shrinks toward the Limiter's MinLimit. Use NewGradient to create one
+type Gradient struct {
+	opts        GradientOpts
+	longLatency float64
+}
+
+// NewGradient creates a new Gradient algorithm
+func NewGradient(opts GradientOpts) *Gradient {
+	if opts.Smoothing <= 0 || opts.Smoothing > 1 {
+		opts.Smoothing = defaultGradientSmoothing
+	}
+	if opts.LongSmoothing <= 0 || opts.LongSmoothing > 1 {
+		opts.LongSmoothing = defaultGradientLongSmoothing
+	}
+	return &Gradient{
+		opts: opts,
+	}
+}
+
+// Update satisfies Algorithm
+func (g *Gradient) Update(limit float64, sample Sample) float64 {
+	var newLimit float64
+	if sample.Dropped {
+		// no room to queue, otherwise small limits would grow
+		newLimit = limit * minimumGradient
+	} else {
+		if sample.Latency <= 0 {
+			return limit
+		}
+		g.trackLatency(sample.Latency)
+		gradient := math.Max(minimumGradient, math.Min(1, g.longLatency/float64(sample.Latency)))
+		queueSize := math.Sqrt(limit)
+		newLimit = limit*gradient + queueSize
+	}
+	return limit*(1-g.opts.Smoothing) + newLimit*g.opts.Smoothing
+}
+
+// trackLatency updates the long-term average latency
+func (g *Gradient) trackLatency(latency time.Duration) {
+	if g.longLatency == 0 {
+		g.longLatency = float64(latency)
+		return
+	}
+	g.longLatency = g.longLatency*(1-g.opts.LongSmoothing) + float64(latency)*g.opts.LongSmoothing
+}
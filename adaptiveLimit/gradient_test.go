@@ -0,0 +1,60 @@
+package adaptiveLimit
+
+import (
+	. "github.com/onsi/gomega"
+	"testing"
+	"time"
+)
+
+func TestGradient_Update(t *testing.T) {
+	cases := map[string]struct {
+		warmUp      []time.Duration
+		limit       float64
+		sample      Sample
+		expectation func(limit float64) OmegaMatcher
+	}{
+		"grows at the healthy latency": {
+			warmUp: []time.Duration{10 * time.Millisecond},
+			limit:  16,
+			sample: Sample{Latency: 10 * time.Millisecond},
+			expectation: func(limit float64) OmegaMatcher {
+				// 16*0.8 + (16*1 + 4)*0.2
+				return BeNumerically("~", 16.8, 0.0001)
+			},
+		},
+		"shrinks when latency doubles": {
+			warmUp: []time.Duration{10 * time.Millisecond},
+			limit:  16,
+			sample: Sample{Latency: 40 * time.Millisecond},
+			expectation: func(limit float64) OmegaMatcher {
+				return BeNumerically("<", limit)
+			},
+		},
+		"shrinks when dropped": {
+			limit:  100,
+			sample: Sample{Dropped: true},
+			expectation: func(limit float64) OmegaMatcher {
+				// 100*0.8 + 100*0.5*0.2
+				return BeNumerically("~", 90, 0.0001)
+			},
+		},
+		"shrinks when dropped at a small limit": {
+			limit:  2,
+			sample: Sample{Dropped: true},
+			expectation: func(limit float64) OmegaMatcher {
+				// 2*0.8 + 2*0.5*0.2
+				return BeNumerically("~", 1.8, 0.0001)
+			},
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			subject := NewGradient(GradientOpts{})
+			for _, latency := range dt.warmUp {
+				subject.trackLatency(latency)
+			}
+			g.Expect(subject.Update(dt.limit, dt.sample)).Should(dt.expectation(dt.limit))
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package adaptiveLimit
+
+import (
+	"errors"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"github.com/wojnosystems/go-time-factory/timeFactory"
+	"math"
+	"sync"
+)
+
+const (
+	defaultInitialLimit = 20
+	defaultMinLimit     = 1
+	defaultMaxLimit     = 1000
+)
+
+// ErrLimitExceeded is returned by Use when the call was rejected because too many calls are already in flight
+var ErrLimitExceeded = errors.New("too many calls in flight")
+
+type Opts struct {
+	// Algorithm learns the limit from each call. Defaults to AIMD with its default options
+	Algorithm Algorithm
+
+	// InitialLimit is the number of calls allowed in flight before anything was learned. Defaults to 20
+	InitialLimit int
+
+	// MinLimit is the fewest calls that will ever be allowed in flight. Defaults to 1
+	MinLimit int
+
+	// MaxLimit is the most calls that will ever be allowed in flight. Defaults to 1000
+	MaxLimit int
+
+	// nowFactory allows the current time to be simulated
+	nowFactory timeFactory.Now
+}
+
+// Limiter caps the number of calls in flight at a limit it learns from the latency and tripping errors of each call.
+// Calls over the limit are rejected with ErrLimitExceeded without being attempted.
+// To use it alongside a breaker, nest one inside the other:
+//
+//	breaker.Use(func() error { return limiter.Use(callback) })
+//
+// ErrLimitExceeded is not a tripping error, so a rejection by the limiter does not count against the breaker.
+// Use New to create one. Limiters are thread-safe.
+type Limiter struct {
+	opts     Opts
+	mu       sync.Mutex
+	limit    float64
+	inFlight int
+}
+
+// New creates a new Limiter
+func New(opts Opts) *Limiter {
+	if opts.Algorithm == nil {
+		opts.Algorithm = NewAIMD(AIMDOpts{})
+	}
+	if opts.MinLimit <= 0 {
+		opts.MinLimit = defaultMinLimit
+	}
+	if opts.MaxLimit <= 0 {
+		opts.MaxLimit = defaultMaxLimit
+	}
+	if opts.InitialLimit <= 0 {
+		opts.InitialLimit = defaultInitialLimit
+	}
+	l := &Limiter{
+		opts: opts,
+	}
+	l.limit = l.bound(float64(opts.InitialLimit))
+	return l
+}
+
+// Use the limiter. If fewer calls than the limit are in flight, the callback is attempted, otherwise
+// ErrLimitExceeded is returned. callback can return any error, errors wrapped in tripping.New() tell the Algorithm
+// the backend is overloaded and are returned unwrapped. All other errors are returned as-is.
+// Use does not block while the callback is being executed.
+func (l *Limiter) Use(callback func() error) error {
+	l.mu.Lock()
+	if l.inFlight >= int(l.limit) {
+		l.mu.Unlock()
+		return ErrLimitExceeded
+	}
+	l.inFlight++
+	inFlight := l.inFlight
+	start := l.opts.nowFactory.Get()
+	l.mu.Unlock()
+
+	err := callback()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	if tripping.IsUnrecorded(err) {
+		return tripping.Strip(err)
+	}
+	l.limit = l.bound(l.opts.Algorithm.Update(l.limit, Sample{
+		Latency:  l.opts.nowFactory.Get().Sub(start),
+		InFlight: inFlight,
+		Dropped:  tripping.IsTripping(err),
+	}))
+	return tripping.Strip(err)
+}
+
+// Limit is the number of calls currently allowed in flight
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+// InFlight is the number of calls currently in flight
+func (l *Limiter) InFlight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inFlight
+}
+
+// bound keeps the limit within MinLimit and MaxLimit
+func (l *Limiter) bound(limit float64) float64 {
+	return math.Min(math.Max(limit, float64(l.opts.MinLimit)), float64(l.opts.MaxLimit))
+}
@@ -0,0 +1,86 @@
+package adaptiveLimit
+
+import (
+	"errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+)
+
+var trippingError = tripping.New(errors.New("tripping error"))
+
+// fixedLimit is an Algorithm that always learns the same limit
+type fixedLimit float64
+
+func (f fixedLimit) Update(_ float64, _ Sample) float64 {
+	return float64(f)
+}
+
+var _ = Describe("Limiter.Use", func() {
+	var (
+		subject *Limiter
+	)
+	BeforeEach(func() {
+		subject = New(Opts{
+			InitialLimit: 2,
+		})
+	})
+	It("attempts calls under the limit", func() {
+		called := false
+		err := subject.Use(func() error {
+			called = true
+			return nil
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(called).Should(BeTrue())
+	})
+	It("rejects calls over the limit", func() {
+		var err error
+		_ = subject.Use(func() error {
+			return subject.Use(func() error {
+				err = subject.Use(func() error {
+					Fail("should not be called")
+					return nil
+				})
+				return nil
+			})
+		})
+		Expect(err).Should(Equal(ErrLimitExceeded))
+	})
+	It("tracks calls in flight", func() {
+		_ = subject.Use(func() error {
+			Expect(subject.InFlight()).Should(Equal(1))
+			return nil
+		})
+		Expect(subject.InFlight()).Should(Equal(0))
+	})
+	It("unwraps tripping errors", func() {
+		err := subject.Use(func() error {
+			return trippingError
+		})
+		Expect(err).Should(Equal(trippingError.Err))
+	})
+	It("shrinks the limit when calls are dropped", func() {
+		subject = New(Opts{InitialLimit: 10})
+		_ = subject.Use(func() error {
+			return trippingError
+		})
+		Expect(subject.Limit()).Should(Equal(9))
+	})
+	When("the algorithm learns a limit out of bounds", func() {
+		It("honors the maximum", func() {
+			subject = New(Opts{Algorithm: fixedLimit(5000), MaxLimit: 100})
+			_ = subject.Use(func() error {
+				return nil
+			})
+			Expect(subject.Limit()).Should(Equal(100))
+		})
+		It("honors the minimum", func() {
+			subject = New(Opts{Algorithm: fixedLimit(0), MinLimit: 3})
+			_ = subject.Use(func() error {
+				return nil
+			})
+			Expect(subject.Limit()).Should(Equal(3))
+		})
+	})
+})
@@ -0,0 +1,69 @@
+package adaptiveLimit
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	defaultVegasAlpha        = 3
+	defaultVegasBeta         = 6
+	defaultVegasBackoffRatio = 0.9
+)
+
+type VegasOpts struct {
+	// Alpha is the estimated queue size below which the limit grows. Defaults to 3
+	Alpha float64
+
+	// Beta is the estimated queue size above which the limit shrinks. Defaults to 6
+	Beta float64
+
+	// BackoffRatio multiplies the limit after each dropped call, from 0 to 1. Defaults to 0.9
+	BackoffRatio float64
+}
+
+// Vegas is based on TCP Vegas. It remembers the fastest latency ever seen as the latency with no queueing, then
+// estimates how many calls are queued from how much slower each call is. The limit grows while the queue is short
+// and shrinks once calls start queueing, often before the backend returns any errors. Use NewVegas to create one
+type Vegas struct {
+	opts          VegasOpts
+	noLoadLatency time.Duration
+}
+
+// NewVegas creates a new Vegas algorithm
+func NewVegas(opts VegasOpts) *Vegas {
+	if opts.Alpha <= 0 {
+		opts.Alpha = defaultVegasAlpha
+	}
+	if opts.Beta <= opts.Alpha {
+		opts.Beta = math.Max(defaultVegasBeta, opts.Alpha*2)
+	}
+	if opts.BackoffRatio <= 0 || opts.BackoffRatio >= 1 {
+		opts.BackoffRatio = defaultVegasBackoffRatio
+	}
+	return &Vegas{
+		opts: opts,
+	}
+}
+
+// Update satisfies Algorithm
+func (v *Vegas) Update(limit float64, sample Sample) float64 {
+	if sample.Dropped {
+		return limit * v.opts.BackoffRatio
+	}
+	if sample.Latency <= 0 {
+		return limit
+	}
+	if v.noLoadLatency == 0 || sample.Latency < v.noLoadLatency {
+		v.noLoadLatency = sample.Latency
+	}
+	queueSize := limit * (1 - float64(v.noLoadLatency)/float64(sample.Latency))
+	switch {
+	case queueSize < v.opts.Alpha:
+		return limit + 1
+	case queueSize > v.opts.Beta:
+		return limit - 1
+	default:
+		return limit
+	}
+}
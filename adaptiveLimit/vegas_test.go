@@ -0,0 +1,50 @@
+package adaptiveLimit
+
+import (
+	. "github.com/onsi/gomega"
+	"testing"
+	"time"
+)
+
+func TestVegas_Update(t *testing.T) {
+	cases := map[string]struct {
+		warmUp   []time.Duration
+		limit    float64
+		sample   Sample
+		expected float64
+	}{
+		"grows without queueing": {
+			warmUp:   []time.Duration{10 * time.Millisecond},
+			limit:    20,
+			sample:   Sample{Latency: 10 * time.Millisecond},
+			expected: 21,
+		},
+		"holds with a short queue": {
+			warmUp:   []time.Duration{10 * time.Millisecond},
+			limit:    20,
+			sample:   Sample{Latency: 13 * time.Millisecond},
+			expected: 20,
+		},
+		"shrinks with a long queue": {
+			warmUp:   []time.Duration{10 * time.Millisecond},
+			limit:    20,
+			sample:   Sample{Latency: 20 * time.Millisecond},
+			expected: 19,
+		},
+		"backs off when dropped": {
+			limit:    20,
+			sample:   Sample{Dropped: true},
+			expected: 18,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			subject := NewVegas(VegasOpts{})
+			for _, latency := range dt.warmUp {
+				subject.Update(dt.limit, Sample{Latency: latency})
+			}
+			g.Expect(subject.Update(dt.limit, dt.sample)).Should(BeNumerically("~", dt.expected, 0.0001))
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package adaptiveThrottle
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestAdaptiveThrottle(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "AdaptiveThrottle Suite")
+}
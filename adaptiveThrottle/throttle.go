@@ -0,0 +1,139 @@
+package adaptiveThrottle
+
+import (
+	"context"
+	"errors"
+	"github.com/wojnosystems/go-circuit-breaker/priority"
+	"github.com/wojnosystems/go-circuit-breaker/slidingWindow"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"github.com/wojnosystems/go-time-factory/timeFactory"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	defaultK            = 2
+	defaultWindow       = 2 * time.Minute
+	defaultWindowBucket = time.Second
+)
+
+// ErrThrottled is returned by Use when the call was rejected without being attempted
+var ErrThrottled = errors.New("request throttled by the client to protect the backend")
+
+type Opts struct {
+	// K is how many requests the throttle sends for each one the backend accepts before it starts rejecting.
+	// Lower values reject more aggressively. Defaults to 2
+	K float64
+
+	// Window is how far back requests and accepts are counted. Defaults to 2 minutes
+	Window time.Duration
+
+	// RandomSource decides which requests are rejected. Leave nil to use math.Rand seeded with the current time.
+	// The source does not need to be thread-safe
+	RandomSource rand.Source
+
+	// nowFactory allows the current time to be simulated
+	nowFactory timeFactory.Now
+}
+
+// Throttle is the adaptive client-side throttle described in the Google SRE book, chapter "Handling Overload".
+// Rather than being open or closed, it rejects each call with probability
+//
+//	max(0, (requests - K * accepts) / (requests + 1))
+//
+// over the window, so traffic falls off smoothly as the backend starts failing and recovers as soon as it accepts
+// calls again. Calls that return a tripping error are not accepts, all other results are.
+// Use New to create one. Throttles are thread-safe.
+type Throttle struct {
+	opts     Opts
+	mu       sync.Mutex
+	requests *slidingWindow.Counter
+	accepts  *slidingWindow.Counter
+	random   *rand.Rand
+}
+
+// New creates a new Throttle
+func New(opts Opts) *Throttle {
+	if opts.K <= 0 {
+		opts.K = defaultK
+	}
+	if opts.Window <= 0 {
+		opts.Window = defaultWindow
+	}
+	randomSource := opts.RandomSource
+	if randomSource == nil {
+		randomSource = rand.NewSource(time.Now().UnixNano())
+	}
+	numberOfBuckets := int(opts.Window / defaultWindowBucket)
+	return &Throttle{
+		opts:     opts,
+		requests: slidingWindow.New(opts.Window, numberOfBuckets),
+		accepts:  slidingWindow.New(opts.Window, numberOfBuckets),
+		random:   rand.New(randomSource),
+	}
+}
+
+// Use the throttle. The callback is attempted unless the throttle randomly rejects it, in which case ErrThrottled
+// is returned. callback can return any error, errors wrapped in tripping.New() are counted as the backend rejecting
+// the call and are returned unwrapped. All other errors are counted as accepts.
+// Use does not block while the callback is being executed.
+func (t *Throttle) Use(callback func() error) error {
+	return t.UseWithPriority(priority.Default, callback)
+}
+
+// UseContext works exactly like UseWithPriority, with the priority set on ctx using priority.WithPriority
+func (t *Throttle) UseContext(ctx context.Context, callback func() error) error {
+	return t.UseWithPriority(priority.FromContext(ctx), callback)
+}
+
+// UseWithPriority works exactly like Use, but higher priority calls are less likely to be rejected, see
+// priority.Chance
+func (t *Throttle) UseWithPriority(p priority.Priority, callback func() error) error {
+	admitted, requestedAt := t.admit(p)
+	if !admitted {
+		return ErrThrottled
+	}
+
+	err := callback()
+	if tripping.IsUnrecorded(err) {
+		// take back the request from the bucket it was counted in, it was neither accepted nor rejected
+		t.mu.Lock()
+		t.requests.Add(requestedAt, -1)
+		t.mu.Unlock()
+		return tripping.Strip(err)
+	}
+	if tripping.IsTripping(err) {
+		return tripping.Strip(err)
+	}
+	t.mu.Lock()
+	t.accepts.Add(t.opts.nowFactory.Get(), 1)
+	t.mu.Unlock()
+	return err
+}
+
+// admit records the request at requestedAt and returns true if it should be attempted
+func (t *Throttle) admit(p priority.Priority) (admitted bool, requestedAt time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.opts.nowFactory.Get()
+	rejectionProbability := t.rejectionProbability(now)
+	t.requests.Add(now, 1)
+	rejectionProbability = 1 - priority.Chance(p, 1-rejectionProbability)
+	return t.random.Float64() >= rejectionProbability, now
+}
+
+// RejectionProbability is the chance the next call will be rejected, from 0 to 1
+func (t *Throttle) RejectionProbability() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.rejectionProbability(t.opts.nowFactory.Get())
+}
+
+// rejectionProbability assumes the lock is held
+func (t *Throttle) rejectionProbability(now time.Time) float64 {
+	requests := t.requests.Sum(now)
+	accepts := t.accepts.Sum(now)
+	return math.Max(0, (requests-t.opts.K*accepts)/(requests+1))
+}
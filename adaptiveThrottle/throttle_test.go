@@ -0,0 +1,150 @@
+package adaptiveThrottle
+
+import (
+	"context"
+	"errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/priority"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"math/rand"
+	"time"
+)
+
+var trippingError = tripping.New(errors.New("tripping error"))
+
+var _ = Describe("Throttle.Use", func() {
+	var (
+		subject *Throttle
+		now     time.Time
+	)
+	BeforeEach(func() {
+		now = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		subject = New(Opts{
+			RandomSource: rand.NewSource(1),
+			nowFactory: func() time.Time {
+				return now
+			},
+		})
+	})
+	When("the backend accepts everything", func() {
+		BeforeEach(func() {
+			for i := 0; i < 100; i++ {
+				_ = subject.Use(func() error {
+					return nil
+				})
+			}
+		})
+		It("never rejects", func() {
+			Expect(subject.RejectionProbability()).Should(Equal(0.0))
+		})
+		It("returns non-tripping errors", func() {
+			err := subject.Use(func() error {
+				return errors.New("not found")
+			})
+			Expect(err).Should(MatchError("not found"))
+		})
+	})
+	When("the backend rejects everything", func() {
+		var (
+			attempted int
+		)
+		BeforeEach(func() {
+			attempted = 0
+			for i := 0; i < 100; i++ {
+				_ = subject.Use(func() error {
+					attempted++
+					return trippingError
+				})
+			}
+		})
+		It("rejects almost every call", func() {
+			Expect(subject.RejectionProbability()).Should(BeNumerically("~", 100.0/101.0, 0.001))
+		})
+		It("still attempts a few calls to detect recovery", func() {
+			Expect(attempted).Should(BeNumerically(">", 0))
+			Expect(attempted).Should(BeNumerically("<", 100))
+		})
+		It("returns ErrThrottled or the unwrapped error", func() {
+			err := subject.Use(func() error {
+				return trippingError
+			})
+			Expect(err).Should(Or(Equal(ErrThrottled), Equal(trippingError.Err)))
+		})
+		When("the window passes", func() {
+			BeforeEach(func() {
+				now = now.Add(defaultWindow)
+			})
+			It("forgets the failures", func() {
+				Expect(subject.RejectionProbability()).Should(Equal(0.0))
+			})
+		})
+	})
+	When("the backend accepts most calls", func() {
+		BeforeEach(func() {
+			for i := 0; i < 100; i++ {
+				i := i
+				_ = subject.Use(func() error {
+					if i%3 == 0 {
+						return trippingError
+					}
+					return nil
+				})
+			}
+		})
+		It("does not reject while accepts keep up with K", func() {
+			Expect(subject.RejectionProbability()).Should(Equal(0.0))
+		})
+	})
+	When("a slow call goes unrecorded", func() {
+		BeforeEach(func() {
+			_ = subject.Use(func() error {
+				now = now.Add(1500 * time.Millisecond)
+				return tripping.Unrecorded(errors.New("cancelled"))
+			})
+		})
+		It("takes back the request from the bucket it was counted in", func() {
+			// the bucket the call was made in has left the window, the bucket it finished in has not
+			now = now.Add(defaultWindow - 1500*time.Millisecond)
+			for i := 0; i < 3; i++ {
+				_ = subject.Use(func() error {
+					return trippingError
+				})
+			}
+			Expect(subject.RejectionProbability()).Should(BeNumerically("~", 0.75, 0.0001))
+		})
+	})
+	When("calls have priorities", func() {
+		// attempted is how many of 1000 calls using use are attempted by a throttle whose backend fails every call
+		attempted := func(use func(callback func() error) error) int {
+			count := 0
+			for i := 0; i < 1000; i++ {
+				_ = use(func() error {
+					count++
+					return trippingError
+				})
+			}
+			return count
+		}
+		withPriority := func(p priority.Priority) func(callback func() error) error {
+			subject = New(Opts{RandomSource: rand.NewSource(1)})
+			return func(callback func() error) error {
+				return subject.UseWithPriority(p, callback)
+			}
+		}
+		It("attempts more critical calls", func() {
+			Expect(attempted(withPriority(priority.Critical))).Should(BeNumerically(">", attempted(withPriority(priority.Default))))
+		})
+		It("attempts fewer sheddable calls", func() {
+			Expect(attempted(withPriority(priority.Sheddable))).Should(BeNumerically("<", attempted(withPriority(priority.Default))))
+		})
+		It("reads the priority from the context", func() {
+			ctx := priority.WithPriority(context.Background(), priority.Critical)
+			subject = New(Opts{RandomSource: rand.NewSource(1)})
+			fromContext := attempted(func(callback func() error) error {
+				return subject.UseContext(ctx, callback)
+			})
+			Expect(fromContext).Should(Equal(attempted(withPriority(priority.Critical))))
+		})
+	})
+})
@@ -0,0 +1,222 @@
+package balancer
+
+import (
+	"context"
+	"errors"
+	"github.com/wojnosystems/go-circuit-breaker/balancer/strategy"
+	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// defaultMaxEjectionPercent is the MaxEjectionPercent when it is not set, the same as Envoy's outlier detection
+	defaultMaxEjectionPercent = 10
+
+	// NoEjectionCap is the MaxEjectionPercent allowing the entire pool to be ejected
+	NoEjectionCap = 100
+)
+
+// ErrNoBackendAvailable is returned by Use when every backend was either ejected or declined to sample the call
+var ErrNoBackendAvailable = errors.New("no backend is available")
+
+// Backend is one member of a pool of equivalent backends
+type Backend struct {
+	// Address identifies the backend. When used with circuitHTTP, this is the base URL, such as "http://10.0.0.1:8080"
+	Address string
+
+	// Breaker protects this backend. Each backend must have its own breaker
+	Breaker *threeStateCircuit.Breaker
+
+	// inFlight is the number of calls currently using this backend, only access atomically
+	inFlight int64
+}
+
+// InFlight is the number of calls currently using this backend
+func (b *Backend) InFlight() int64 {
+	return atomic.LoadInt64(&b.inFlight)
+}
+
+type Opts struct {
+	// Strategy decides which backend is tried first. Defaults to RoundRobin
+	Strategy strategy.Strategy
+
+	// MaxEjectionPercent caps the percentage of the pool, from 0 to 100, that may be ejected for having an open breaker.
+	// When more backends than this are open, the ones closest to leaving the open state are kept in rotation and
+	// called without consulting their breakers, so a widespread outage degrades rather than rejecting everything.
+	// Their calls are still recorded by their breakers, see threeStateCircuit.Breaker.UseForced.
+	// At least one backend may always be ejected. Defaults to 10, set to NoEjectionCap to allow the entire pool to be
+	// ejected
+	MaxEjectionPercent float64
+
+	// RandomSource is used by PowerOfTwoChoices. Leave nil to use math.Rand seeded with the current time.
+	// The source does not need to be thread-safe
+	RandomSource rand.Source
+}
+
+// Balancer spreads calls across a pool of backends. Backends whose breakers are open are ejected from rotation
+// and backends whose breakers are half-open only receive the calls their breaker samples.
+// Use New to create one
+type Balancer struct {
+	opts     Opts
+	backends []*Backend
+
+	// nextRoundRobin is the index of the next backend to try first, only access atomically
+	nextRoundRobin uint64
+
+	randomMu sync.Mutex
+	random   *rand.Rand
+}
+
+// New creates a Balancer over the backends
+func New(opts Opts, backends ...*Backend) *Balancer {
+	randomSource := opts.RandomSource
+	if randomSource == nil {
+		randomSource = rand.NewSource(time.Now().UnixNano())
+	}
+	return &Balancer{
+		opts:     opts,
+		backends: backends,
+		random:   rand.New(randomSource),
+	}
+}
+
+// Backends are the backends this balancer spreads calls across
+func (b *Balancer) Backends() []*Backend {
+	return b.backends
+}
+
+// Use calls callback with exactly one backend chosen by the Strategy, through that backend's breaker.
+// If the chosen backend's breaker declines to sample the call, the next candidate is tried.
+// callback can return any error, errors wrapped in tripping.New() count against the chosen backend's breaker.
+// Returns ErrNoBackendAvailable if no backend would accept the call
+func (b *Balancer) Use(callback func(backend *Backend) error) error {
+	return b.UseContext(context.Background(), callback)
+}
+
+// UseContext works exactly like Use, but passes ctx to the chosen backend's breaker so it admits higher priority calls
+// first while it recovers, see priority.WithPriority
+func (b *Balancer) UseContext(ctx context.Context, callback func(backend *Backend) error) error {
+	ejected, forced := b.ejections()
+	for _, backend := range b.candidates() {
+		if ejected[backend] {
+			continue
+		}
+		if forced[backend] {
+			// too much of the pool is open to eject this backend, call it even though its breaker would reject it
+			return backend.Breaker.UseForced(func() error {
+				return call(backend, callback)
+			})
+		}
+		called := false
+		err := backend.Breaker.UseContext(ctx, func() error {
+			called = true
+			return call(backend, callback)
+		})
+		if called {
+			return err
+		}
+	}
+	return ErrNoBackendAvailable
+}
+
+// call invokes callback while tracking in-flight calls for the backend
+func call(backend *Backend, callback func(backend *Backend) error) error {
+	atomic.AddInt64(&backend.inFlight, 1)
+	defer atomic.AddInt64(&backend.inFlight, -1)
+	return callback(backend)
+}
+
+// ejections finds the backends with open breakers. ejected backends are skipped. forced backends are open, but
+// are kept in rotation because ejecting them would exceed MaxEjectionPercent.
+func (b *Balancer) ejections() (ejected map[*Backend]bool, forced map[*Backend]bool) {
+	type openBackend struct {
+		backend    *Backend
+		retryAfter time.Duration
+	}
+	open := make([]openBackend, 0, len(b.backends))
+	for _, backend := range b.backends {
+		if retryAfter := backend.Breaker.RetryAfter(); retryAfter > 0 {
+			open = append(open, openBackend{backend: backend, retryAfter: retryAfter})
+		}
+	}
+	ejected = make(map[*Backend]bool, len(open))
+	forced = make(map[*Backend]bool)
+	maxEjected := b.maxEjected()
+	if len(open) > maxEjected {
+		// keep the backends that will recover soonest in rotation
+		sort.Slice(open, func(i, j int) bool {
+			return open[i].retryAfter < open[j].retryAfter
+		})
+		for _, o := range open[:len(open)-maxEjected] {
+			forced[o.backend] = true
+		}
+		open = open[len(open)-maxEjected:]
+	}
+	for _, o := range open {
+		ejected[o.backend] = true
+	}
+	return
+}
+
+// maxEjected is the number of backends that may be ejected at once
+func (b *Balancer) maxEjected() int {
+	percent := b.opts.MaxEjectionPercent
+	if percent <= 0 {
+		percent = defaultMaxEjectionPercent
+	}
+	if percent >= NoEjectionCap {
+		return len(b.backends)
+	}
+	maxEjected := int(float64(len(b.backends)) * percent / 100)
+	if maxEjected < 1 && len(b.backends) > 0 {
+		maxEjected = 1
+	}
+	return maxEjected
+}
+
+// candidates orders the backends by preference according to the Strategy
+func (b *Balancer) candidates() []*Backend {
+	ordered := b.roundRobinOrder()
+	switch b.opts.Strategy {
+	case strategy.LeastLoaded:
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return ordered[i].InFlight() < ordered[j].InFlight()
+		})
+	case strategy.PowerOfTwoChoices:
+		if len(ordered) > 2 {
+			b.randomMu.Lock()
+			first := b.random.Intn(len(ordered))
+			second := b.random.Intn(len(ordered) - 1)
+			b.randomMu.Unlock()
+			if second >= first {
+				second++
+			}
+			ordered[0], ordered[first] = ordered[first], ordered[0]
+			if second == 0 {
+				second = first
+			}
+			ordered[1], ordered[second] = ordered[second], ordered[1]
+		}
+		if len(ordered) > 1 && ordered[1].InFlight() < ordered[0].InFlight() {
+			ordered[0], ordered[1] = ordered[1], ordered[0]
+		}
+	}
+	return ordered
+}
+
+// roundRobinOrder returns all backends, starting with the next in the rotation
+func (b *Balancer) roundRobinOrder() []*Backend {
+	ordered := make([]*Backend, len(b.backends))
+	if len(b.backends) == 0 {
+		return ordered
+	}
+	start := int((atomic.AddUint64(&b.nextRoundRobin, 1) - 1) % uint64(len(b.backends)))
+	for i := range b.backends {
+		ordered[i] = b.backends[(start+i)%len(b.backends)]
+	}
+	return ordered
+}
@@ -0,0 +1,13 @@
+package balancer
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestBalancer(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Balancer Suite")
+}
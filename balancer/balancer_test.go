@@ -0,0 +1,237 @@
+package balancer
+
+import (
+	"context"
+	"errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/balancer/strategy"
+	"github.com/wojnosystems/go-circuit-breaker/priority"
+	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+var trippingError = tripping.New(errors.New("tripping error"))
+
+func newBackends(addresses ...string) []*Backend {
+	backends := make([]*Backend, len(addresses))
+	for i, address := range addresses {
+		backends[i] = &Backend{
+			Address: address,
+			Breaker: threeStateCircuit.New(threeStateCircuit.Opts{
+				OpenDuration: 1 * time.Hour,
+			}),
+		}
+	}
+	return backends
+}
+
+func trip(backend *Backend) {
+	_ = backend.Breaker.Use(func() error {
+		return trippingError
+	})
+}
+
+// record calls Use n times and returns the address of each backend used
+func record(subject *Balancer, n int) (addresses []string) {
+	for i := 0; i < n; i++ {
+		_ = subject.Use(func(backend *Backend) error {
+			addresses = append(addresses, backend.Address)
+			return nil
+		})
+	}
+	return
+}
+
+// criticalOnly samples only Critical calls while half-open
+type criticalOnly struct{}
+
+func (criticalOnly) Sample(sampleContext threeStateCircuit.SampleContext) bool {
+	return sampleContext.Priority == priority.Critical
+}
+
+var _ = Describe("Balancer.Use", func() {
+	var (
+		backends []*Backend
+		opts     Opts
+		subject  *Balancer
+	)
+	BeforeEach(func() {
+		backends = newBackends("a", "b", "c")
+		opts = Opts{}
+	})
+	JustBeforeEach(func() {
+		subject = New(opts, backends...)
+	})
+	When("round robin", func() {
+		It("rotates through every backend", func() {
+			Expect(record(subject, 4)).Should(Equal([]string{"a", "b", "c", "a"}))
+		})
+		When("a backend is open", func() {
+			BeforeEach(func() {
+				trip(backends[1])
+			})
+			It("ejects it from rotation", func() {
+				Expect(record(subject, 3)).Should(Equal([]string{"a", "c", "c"}))
+			})
+		})
+		When("every backend is open", func() {
+			BeforeEach(func() {
+				for _, backend := range backends {
+					trip(backend)
+				}
+			})
+			It("keeps all but one in rotation by default", func() {
+				Expect(record(subject, 3)).Should(HaveLen(3))
+			})
+			When("ejection is not capped", func() {
+				BeforeEach(func() {
+					opts.MaxEjectionPercent = NoEjectionCap
+				})
+				It("has no backend available", func() {
+					err := subject.Use(func(_ *Backend) error {
+						return nil
+					})
+					Expect(err).Should(Equal(ErrNoBackendAvailable))
+				})
+			})
+			When("ejection is capped", func() {
+				BeforeEach(func() {
+					opts.MaxEjectionPercent = 50
+				})
+				It("keeps some backends in rotation", func() {
+					Expect(record(subject, 3)).Should(HaveLen(3))
+				})
+				It("returns errors without wrapping", func() {
+					err := subject.Use(func(_ *Backend) error {
+						return trippingError
+					})
+					Expect(err).Should(Equal(trippingError.Err))
+				})
+				It("records the calls through the backend's breaker", func() {
+					var used *Backend
+					_ = subject.Use(func(backend *Backend) error {
+						used = backend
+						return nil
+					})
+					Expect(used.Breaker.CircuitState()).ShouldNot(Equal("Open"))
+					Expect(used.Breaker.Decisions().Admitted).Should(Equal(uint64(2)))
+				})
+			})
+		})
+		When("a backend is half-open and does not sample", func() {
+			BeforeEach(func() {
+				backends[0].Breaker = threeStateCircuit.New(threeStateCircuit.Opts{
+					OpenDuration: 1 * time.Nanosecond,
+					HalfOpenSampler: threeStateCircuit.ShouldSample(func(_ time.Duration) bool {
+						return false
+					}),
+				})
+				trip(backends[0])
+				time.Sleep(1 * time.Millisecond)
+			})
+			It("tries the next backend", func() {
+				Expect(record(subject, 1)).Should(Equal([]string{"b"}))
+			})
+		})
+		When("a backend is half-open and only samples critical calls", func() {
+			BeforeEach(func() {
+				backends[0].Breaker = threeStateCircuit.New(threeStateCircuit.Opts{
+					OpenDuration: 1 * time.Nanosecond,
+					Sampler:      criticalOnly{},
+				})
+				trip(backends[0])
+				time.Sleep(1 * time.Millisecond)
+			})
+			It("passes the priority to the backend's breaker", func() {
+				var address string
+				_ = subject.UseContext(priority.WithPriority(context.Background(), priority.Critical), func(backend *Backend) error {
+					address = backend.Address
+					return nil
+				})
+				Expect(address).Should(Equal("a"))
+			})
+		})
+	})
+	When("least loaded", func() {
+		BeforeEach(func() {
+			opts.Strategy = strategy.LeastLoaded
+			backends[0].inFlight = 2
+			backends[1].inFlight = 1
+		})
+		It("chooses the backend with the fewest calls in flight", func() {
+			Expect(record(subject, 1)).Should(Equal([]string{"c"}))
+		})
+	})
+	When("power of two choices", func() {
+		BeforeEach(func() {
+			opts.Strategy = strategy.PowerOfTwoChoices
+			opts.RandomSource = rand.NewSource(1)
+			backends[0].inFlight = 5
+			backends[1].inFlight = 5
+		})
+		It("prefers the idle backend whenever it is one of the choices", func() {
+			chosen := 0
+			for _, address := range record(subject, 30) {
+				if address == "c" {
+					chosen++
+				}
+			}
+			// c is one of the two choices 2/3 of the time
+			Expect(chosen).Should(BeNumerically(">", 10))
+		})
+		When("there are only two backends", func() {
+			BeforeEach(func() {
+				backends = backends[:2]
+				backends[1].inFlight = 0
+			})
+			It("always chooses the least loaded", func() {
+				Expect(record(subject, 3)).Should(Equal([]string{"b", "b", "b"}))
+			})
+		})
+		It("tracks calls in flight", func() {
+			_ = subject.Use(func(backend *Backend) error {
+				Expect(backend.InFlight()).Should(BeEquivalentTo(1 + map[string]int{"a": 5, "b": 5, "c": 0}[backend.Address]))
+				return nil
+			})
+		})
+	})
+})
+
+func TestBalancer_maxEjected(t *testing.T) {
+	cases := map[string]struct {
+		percent  float64
+		expected int
+	}{
+		"unset, then 10%": {
+			percent:  0,
+			expected: 1,
+		},
+		"no cap": {
+			percent:  NoEjectionCap,
+			expected: 10,
+		},
+		"at least one": {
+			percent:  5,
+			expected: 1,
+		},
+		"half": {
+			percent:  50,
+			expected: 5,
+		},
+		"rounds down": {
+			percent:  15,
+			expected: 1,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			subject := New(Opts{MaxEjectionPercent: dt.percent}, make([]*Backend, 10)...)
+			g.Expect(subject.maxEjected()).Should(Equal(dt.expected))
+		})
+	}
+}
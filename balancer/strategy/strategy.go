@@ -0,0 +1,12 @@
+//go:generate go-enum --file=$GOFILE -noprefix
+
+package strategy
+
+// Strategy is how the balancer chooses a backend for each call
+/* ENUM(
+RoundRobin,
+LeastLoaded,
+PowerOfTwoChoices
+)
+*/
+type Strategy uint8
@@ -0,0 +1,66 @@
+package circuitHTTP
+
+import (
+	"context"
+	"github.com/wojnosystems/go-circuit-breaker/balancer"
+	"net/http"
+	"net/url"
+)
+
+// BalancedTransport is a http.RoundTripper that spreads requests across a balancer's backends. Each backend's Address
+// is the base URL requests are rewritten to, in the same way as Failover endpoints. Every attempt, including retries
+// and hedges, is balanced separately, so may be sent to a different backend.
+// Use NewBalancedTransport instead of using this struct as the balancer requires initialization
+type BalancedTransport struct {
+	base     http.RoundTripper
+	balancer *balancedBreaker
+	opts     Opts
+}
+
+// NewBalancedTransport sends requests through base to the backends of pool. If base is nil, http.DefaultTransport is used.
+// When every backend is ejected, the round trip fails with balancer.ErrNoBackendAvailable, or, if RespondWhenOpen
+// is set, a synthesized 503 response.
+func NewBalancedTransport(pool *balancer.Balancer, base http.RoundTripper, opts Opts) *BalancedTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &BalancedTransport{
+		base:     base,
+		balancer: &balancedBreaker{pool: pool},
+		opts:     opts,
+	}
+}
+
+// RoundTrip satisfies http.RoundTripper
+func (t *BalancedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.opts.do(t.balancer, req, t.base.RoundTrip)
+	if resp != nil {
+		return resp, nil
+	}
+	return nil, err
+}
+
+// balancedBreaker sends each request to a backend chosen by the pool, through that backend's breaker
+type balancedBreaker struct {
+	pool *balancer.Balancer
+}
+
+// Use satisfies Breaker, callback is called with whichever backend the pool chose
+func (b *balancedBreaker) Use(callback func() error) error {
+	return b.pool.Use(func(_ *balancer.Backend) error {
+		return callback()
+	})
+}
+
+// route satisfies router, pointing the request at the chosen backend
+func (b *balancedBreaker) route(ctx context.Context, req *http.Request, callback func(routed *http.Request, breaker Breaker) error) error {
+	return b.pool.UseContext(ctx, func(backend *balancer.Backend) error {
+		baseURL, err := url.Parse(backend.Address)
+		if err != nil {
+			return err
+		}
+		backendReq := req.Clone(req.Context())
+		rewriteURL(backendReq, baseURL)
+		return callback(backendReq, backend.Breaker)
+	})
+}
@@ -0,0 +1,151 @@
+package circuitHTTP_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/wojnosystems/go-circuit-breaker/balancer"
+	"github.com/wojnosystems/go-circuit-breaker/circuitHTTP"
+	"github.com/wojnosystems/go-circuit-breaker/priority"
+	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit"
+	"net/http"
+	"time"
+)
+
+var _ = Describe("BalancedTransport", func() {
+	var (
+		first    *ghttp.Server
+		second   *ghttp.Server
+		backends []*balancer.Backend
+		opts     circuitHTTP.Opts
+		client   *http.Client
+	)
+	BeforeEach(func() {
+		first = ghttp.NewServer()
+		second = ghttp.NewServer()
+		backends = []*balancer.Backend{
+			{
+				Address: first.URL(),
+				Breaker: threeStateCircuit.New(threeStateCircuit.Opts{OpenDuration: 1 * time.Hour}),
+			},
+			{
+				Address: second.URL(),
+				Breaker: threeStateCircuit.New(threeStateCircuit.Opts{OpenDuration: 1 * time.Hour}),
+			},
+		}
+		opts = circuitHTTP.Opts{}
+	})
+	JustBeforeEach(func() {
+		client = &http.Client{
+			Transport: circuitHTTP.NewBalancedTransport(balancer.New(balancer.Opts{MaxEjectionPercent: balancer.NoEjectionCap}, backends...), nil, opts),
+		}
+	})
+	AfterEach(func() {
+		first.Close()
+		second.Close()
+	})
+	It("spreads requests across backends", func() {
+		first.AppendHandlers(ghttp.VerifyRequest(http.MethodGet, "/things/1"))
+		second.AppendHandlers(ghttp.VerifyRequest(http.MethodGet, "/things/2"))
+		_, err := client.Get("http://pool/things/1")
+		Expect(err).ShouldNot(HaveOccurred())
+		_, err = client.Get("http://pool/things/2")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(first.ReceivedRequests()).Should(HaveLen(1))
+		Expect(second.ReceivedRequests()).Should(HaveLen(1))
+	})
+	When("a backend trips", func() {
+		BeforeEach(func() {
+			first.AppendHandlers(ghttp.RespondWith(http.StatusBadGateway, nil))
+			second.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, nil),
+				ghttp.RespondWith(http.StatusOK, nil),
+			)
+		})
+		It("ejects it", func() {
+			resp, err := client.Get("http://pool/")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(resp.StatusCode).Should(Equal(http.StatusBadGateway))
+			for i := 0; i < 2; i++ {
+				resp, err = client.Get("http://pool/")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(resp.StatusCode).Should(Equal(http.StatusOK))
+			}
+			Expect(first.ReceivedRequests()).Should(HaveLen(1))
+		})
+	})
+	When("retrying", func() {
+		BeforeEach(func() {
+			opts.Retrier = circuitHTTP.NewRetrier(circuitHTTP.RetryOpts{
+				InitialBackoff: 1 * time.Millisecond,
+			})
+			first.AppendHandlers(ghttp.RespondWith(http.StatusBadGateway, nil))
+			second.AppendHandlers(ghttp.RespondWith(http.StatusOK, nil))
+		})
+		It("balances the retry to another backend", func() {
+			resp, err := client.Get("http://pool/")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(resp.StatusCode).Should(Equal(http.StatusOK))
+			Expect(first.ReceivedRequests()).Should(HaveLen(1))
+			Expect(second.ReceivedRequests()).Should(HaveLen(1))
+		})
+	})
+	When("the backends are recovering", func() {
+		BeforeEach(func() {
+			opts.PriorityHeader = "Priority"
+			for i := range backends {
+				backends[i].Breaker = threeStateCircuit.New(threeStateCircuit.Opts{
+					OpenDuration: 10 * time.Millisecond,
+					Sampler:      criticalOnly{},
+				})
+				_ = backends[i].Breaker.Use(func() error {
+					return trippingError
+				})
+			}
+			time.Sleep(20 * time.Millisecond)
+			first.AppendHandlers(ghttp.RespondWith(http.StatusOK, nil))
+			second.AppendHandlers(ghttp.RespondWith(http.StatusOK, nil))
+		})
+		It("admits requests by the priority header", func() {
+			req, _ := http.NewRequest(http.MethodGet, "http://pool/", nil)
+			req.Header.Set("Priority", priority.Sheddable.String())
+			_, err := client.Do(req)
+			Expect(err).Should(MatchError(ContainSubstring(balancer.ErrNoBackendAvailable.Error())))
+
+			req.Header.Set("Priority", priority.Critical.String())
+			resp, err := client.Do(req)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(resp.StatusCode).Should(Equal(http.StatusOK))
+		})
+	})
+	When("every backend is ejected", func() {
+		BeforeEach(func() {
+			for _, backend := range backends {
+				_ = backend.Breaker.Use(func() error {
+					return trippingError
+				})
+			}
+		})
+		It("fails", func() {
+			_, err := client.Get("http://pool/")
+			Expect(err).Should(HaveOccurred())
+		})
+		When("responding when open", func() {
+			BeforeEach(func() {
+				opts.RespondWhenOpen = true
+			})
+			It("synthesizes a response", func() {
+				resp, err := client.Get("http://pool/")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(circuitHTTP.IsRejection(resp)).Should(BeTrue())
+			})
+		})
+	})
+})
+
+// criticalOnly samples only Critical requests while half-open
+type criticalOnly struct{}
+
+func (criticalOnly) Sample(sampleContext threeStateCircuit.SampleContext) bool {
+	return sampleContext.Priority == priority.Critical
+}
@@ -0,0 +1,23 @@
+package circuitHTTP_test
+
+import (
+	"github.com/wojnosystems/go-circuit-breaker/adaptiveLimit"
+	"github.com/wojnosystems/go-circuit-breaker/adaptiveThrottle"
+	"github.com/wojnosystems/go-circuit-breaker/circuitHTTP"
+	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit"
+	"github.com/wojnosystems/go-circuit-breaker/twoStateCircuit"
+)
+
+// every breaker in this module can protect a Client
+var (
+	_ circuitHTTP.Breaker        = (*twoStateCircuit.Breaker)(nil)
+	_ circuitHTTP.StateDescriber = (*twoStateCircuit.Breaker)(nil)
+	_ circuitHTTP.Breaker        = (*threeStateCircuit.Breaker)(nil)
+	_ circuitHTTP.StateDescriber = (*threeStateCircuit.Breaker)(nil)
+	_ circuitHTTP.Breaker        = (*adaptiveThrottle.Throttle)(nil)
+	_ circuitHTTP.ContextBreaker = (*adaptiveThrottle.Throttle)(nil)
+	_ circuitHTTP.ContextBreaker = (*threeStateCircuit.Breaker)(nil)
+	_ circuitHTTP.Classifier     = (*twoStateCircuit.Breaker)(nil)
+	_ circuitHTTP.Classifier     = (*threeStateCircuit.Breaker)(nil)
+	_ circuitHTTP.Breaker        = (*adaptiveLimit.Limiter)(nil)
+)
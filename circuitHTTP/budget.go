@@ -0,0 +1,91 @@
+package circuitHTTP
+
+import "sync"
+
+const (
+	// defaultBudgetCapacity is how many extra requests may be saved up when Capacity is not set
+	defaultBudgetCapacity = 10
+
+	// budgetTolerance absorbs floating point error, so 10 deposits with a Ratio of 0.1 earn a whole token
+	budgetTolerance = 1e-9
+)
+
+type BudgetOpts struct {
+	// Ratio is how many extra requests may be sent for every request, such as 0.1 for at most 10% extra load
+	Ratio float64
+
+	// Capacity is the most extra requests that may be saved up during quiet periods and then sent in a burst.
+	// Defaults to 10, and is at least 1 so an extra request can always be saved up
+	Capacity float64
+
+	// InitialTokens in the budget, up to the Capacity. Prime the budget so extra requests can be sent before any
+	// requests were made
+	InitialTokens float64
+}
+
+// Budget limits extra requests, such as hedges and retries, to a fraction of the requests being made.
+// Every request deposits Ratio tokens and every extra request withdraws a whole token.
+// Share a single Budget between everything that sends requests through the same breaker to cap the extra load
+// placed on its backend. Use NewBudget to create one. Budgets are thread-safe.
+type Budget struct {
+	opts   BudgetOpts
+	mu     sync.Mutex
+	tokens float64
+}
+
+// NewBudget creates a new Budget
+func NewBudget(opts BudgetOpts) *Budget {
+	if opts.Capacity <= 0 {
+		opts.Capacity = defaultBudgetCapacity
+	} else if opts.Capacity < 1 {
+		opts.Capacity = 1
+	}
+	if opts.InitialTokens > opts.Capacity {
+		opts.InitialTokens = opts.Capacity
+	}
+	return &Budget{
+		opts:   opts,
+		tokens: opts.InitialTokens,
+	}
+}
+
+// Deposit records that a request was made, earning Ratio tokens up to the Capacity
+func (b *Budget) Deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens += b.opts.Ratio
+	if b.tokens > b.opts.Capacity {
+		b.tokens = b.opts.Capacity
+	}
+}
+
+// Withdraw returns true, spending a token, if an extra request may be sent
+func (b *Budget) Withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1-budgetTolerance {
+		return false
+	}
+	b.tokens--
+	if b.tokens < 0 {
+		b.tokens = 0
+	}
+	return true
+}
+
+// refund returns a token withdrawn for an extra request that was not sent after all
+func (b *Budget) refund() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens++
+	if b.tokens > b.opts.Capacity {
+		b.tokens = b.opts.Capacity
+	}
+}
+
+// Tokens is the number of extra requests that may currently be sent
+func (b *Budget) Tokens() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens
+}
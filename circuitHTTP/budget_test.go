@@ -0,0 +1,80 @@
+package circuitHTTP
+
+import (
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestBudget_Withdraw(t *testing.T) {
+	cases := map[string]struct {
+		opts     BudgetOpts
+		deposits int
+		expected []bool
+	}{
+		"empty": {
+			opts:     BudgetOpts{Ratio: 0.1, Capacity: 10},
+			expected: []bool{false},
+		},
+		"primed": {
+			opts:     BudgetOpts{Ratio: 0.1, Capacity: 10, InitialTokens: 1},
+			expected: []bool{true, false},
+		},
+		"ten percent": {
+			opts:     BudgetOpts{Ratio: 0.1, Capacity: 10},
+			deposits: 25,
+			expected: []bool{true, true, false},
+		},
+		"default capacity": {
+			opts:     BudgetOpts{Ratio: 0.1},
+			deposits: 1000,
+			expected: append(repeat(true, 10), false),
+		},
+		"capacity of at least 1": {
+			opts:     BudgetOpts{Ratio: 1, Capacity: 0.5},
+			deposits: 10,
+			expected: []bool{true, false},
+		},
+		"initial tokens up to the capacity": {
+			opts:     BudgetOpts{Capacity: 2, InitialTokens: 5},
+			expected: []bool{true, true, false},
+		},
+		"initial tokens survive a deposit": {
+			opts:     BudgetOpts{Ratio: 0.1, InitialTokens: 2},
+			deposits: 1,
+			expected: []bool{true, true, false},
+		},
+		"whole tokens despite rounding": {
+			opts:     BudgetOpts{Ratio: 0.1},
+			deposits: 10,
+			expected: []bool{true, false},
+		},
+		"capped": {
+			opts:     BudgetOpts{Ratio: 1, Capacity: 2},
+			deposits: 10,
+			expected: []bool{true, true, false},
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			subject := NewBudget(dt.opts)
+			for i := 0; i < dt.deposits; i++ {
+				subject.Deposit()
+			}
+			actual := make([]bool, len(dt.expected))
+			for i := range actual {
+				actual[i] = subject.Withdraw()
+			}
+			g.Expect(actual).Should(Equal(dt.expected))
+		})
+	}
+}
+
+// repeat creates a slice with the value times times
+func repeat(value bool, times int) []bool {
+	values := make([]bool, times)
+	for i := range values {
+		values[i] = value
+	}
+	return values
+}
@@ -0,0 +1,41 @@
+package circuitHTTP
+
+import (
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClassifyServerResponse_ClassifyServerResponse(t *testing.T) {
+	cases := map[string]struct {
+		classifier       ClassifyServerResponse
+		statusCode       int
+		expectedTripping bool
+	}{
+		"default ok does not trip": {
+			statusCode: http.StatusOK,
+		},
+		"default client error does not trip": {
+			statusCode: http.StatusNotFound,
+		},
+		"default server error trips": {
+			statusCode:       http.StatusBadGateway,
+			expectedTripping: true,
+		},
+		"overridden": {
+			classifier: func(_ int, _ time.Duration) error {
+				return nil
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			actual := tripping.IsTripping(dt.classifier.ClassifyServerResponse(dt.statusCode, 1*time.Second))
+			g.Expect(actual).Should(Equal(dt.expectedTripping))
+		})
+	}
+}
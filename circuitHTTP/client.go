@@ -8,35 +8,38 @@ import (
 )
 
 // Client is a http.Client with a circuit breaker inside. Every request that fails is counted in the breaker
-// Use New or NewWithTripDecider instead of using this struct as breaker and tripDecider require initialization
+// Use New, NewWithTripDecider or NewWithOpts instead of using this struct as breaker and opts require initialization
 type Client struct {
 	*http.Client
-	breaker     Breaker
-	tripDecider ConvertToTrippingErrIfShould
+	breaker Breaker
+	opts    Opts
 }
 
 // New creates a new http.Client with a breaker inside
 // by default, the breaker can trip when the client receives timeouts or http statuses that usually indicate
 // an outage or rate limit.
 func New(breaker Breaker, client *http.Client) *Client {
-	return NewWithTripDecider(breaker, client, defaultConvertToTrippingErrIfShould)
+	return NewWithOpts(breaker, client, Opts{})
 }
 
 // NewWithTripDecider is like New, but allows you to customize which http statuses or errors trip the breaker
 func NewWithTripDecider(breaker Breaker, client *http.Client, tripDecider ConvertToTrippingErrIfShould) *Client {
+	return NewWithOpts(breaker, client, Opts{
+		TripDecider: tripDecider,
+	})
+}
+
+// NewWithOpts is like New, but allows you to customize every option
+func NewWithOpts(breaker Breaker, client *http.Client, opts Opts) *Client {
 	return &Client{
-		Client:      client,
-		breaker:     breaker,
-		tripDecider: tripDecider,
+		Client:  client,
+		breaker: breaker,
+		opts:    opts,
 	}
 }
 
 func (c *Client) Do(req *http.Request) (resp *http.Response, err error) {
-	err = c.breaker.Use(func() error {
-		resp, err = c.Client.Do(req)
-		return c.tripDecider.ConvertToTrippingErrIfShould(resp, err)
-	})
-	return
+	return c.opts.do(c.breaker, req, c.Client.Do)
 }
 
 func (c *Client) Get(url string) (resp *http.Response, err error) {
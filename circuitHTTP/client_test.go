@@ -1,17 +1,23 @@
 package circuitHTTP_test
 
 import (
+	"errors"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/ghttp"
 	"github.com/wojnosystems/go-circuit-breaker/circuitHTTP"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
 	"github.com/wojnosystems/go-circuit-breaker/twoStateCircuit"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"time"
 )
 
 const badURL = "\a"
 
+var trippingError = tripping.New(errors.New("tripping error"))
+
 var _ = Describe("Client", func() {
 	var (
 		server *ghttp.Server
@@ -84,4 +90,80 @@ var _ = Describe("Client", func() {
 			Expect(resp.StatusCode).Should(Equal(http.StatusOK))
 		})
 	})
+	When("the breaker is open", func() {
+		var (
+			breaker *twoStateCircuit.Breaker
+		)
+		BeforeEach(func() {
+			breaker = twoStateCircuit.New(twoStateCircuit.Opts{
+				OpenDuration: 1 * time.Hour,
+			})
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusBadGateway, nil),
+			)
+		})
+		When("responding with errors", func() {
+			BeforeEach(func() {
+				client = circuitHTTP.New(breaker, http.DefaultClient)
+				_, _ = client.Get(server.URL())
+			})
+			It("returns the breaker's error without a response", func() {
+				resp, err := client.Get(server.URL())
+				Expect(err).Should(HaveOccurred())
+				Expect(resp).Should(BeNil())
+			})
+		})
+		When("responding when open", func() {
+			BeforeEach(func() {
+				client = circuitHTTP.NewWithOpts(breaker, http.DefaultClient, circuitHTTP.Opts{
+					RespondWhenOpen: true,
+				})
+				_, _ = client.Get(server.URL())
+			})
+			It("synthesizes a service unavailable response", func() {
+				resp, err := client.Get(server.URL())
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(resp.StatusCode).Should(Equal(http.StatusServiceUnavailable))
+				Expect(resp.Header.Get(circuitHTTP.CircuitStateHeader)).Should(Equal("Open"))
+				Expect(resp.Header.Get("Retry-After")).Should(Equal("3600"))
+				Expect(resp.Header.Get("Content-Type")).Should(Equal("application/problem+json"))
+			})
+			It("is detectable as a rejection", func() {
+				resp, _ := client.Get(server.URL())
+				Expect(circuitHTTP.IsRejection(resp)).Should(BeTrue())
+				Expect(circuitHTTP.RejectionCause(resp)).Should(HaveOccurred())
+			})
+			It("has a problem details body", func() {
+				resp, _ := client.Get(server.URL())
+				body, err := ioutil.ReadAll(resp.Body)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(body).Should(MatchJSON(`{
+					"type": "about:blank",
+					"title": "Service Unavailable",
+					"status": 503,
+					"detail": "upstream service is down or is rateLimit-limiting",
+					"circuitState": "Open"
+				}`))
+			})
+			It("does not contact the server", func() {
+				_, _ = client.Get(server.URL())
+				Expect(server.ReceivedRequests()).Should(HaveLen(1))
+			})
+		})
+	})
+	When("the server responds", func() {
+		BeforeEach(func() {
+			client = circuitHTTP.NewWithOpts(twoStateCircuit.New(twoStateCircuit.Opts{}), http.DefaultClient, circuitHTTP.Opts{
+				RespondWhenOpen: true,
+			})
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusServiceUnavailable, nil),
+			)
+		})
+		It("is not a rejection", func() {
+			resp, _ := client.Get(server.URL())
+			Expect(circuitHTTP.IsRejection(resp)).Should(BeFalse())
+			Expect(circuitHTTP.RejectionCause(resp)).ShouldNot(HaveOccurred())
+		})
+	})
 })
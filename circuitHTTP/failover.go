@@ -0,0 +1,143 @@
+package circuitHTTP
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// ErrNoEndpoints is returned by NewFailover when it is not given any endpoints to send requests to
+var ErrNoEndpoints = errors.New("failover requires at least one endpoint")
+
+// Endpoint is one of the base URLs a Failover may send requests to. Each endpoint has its own breaker so an outage
+// in one region does not open the circuit for the others.
+type Endpoint struct {
+	// BaseURL is the scheme, host and optional path prefix requests are rewritten to, such as "https://us-east.example.com/api"
+	BaseURL string
+
+	// Breaker protects this endpoint
+	Breaker Breaker
+
+	baseURL *url.URL
+}
+
+// Failover sends each request to the first endpoint, in order, whose breaker will accept it.
+// When an endpoint's breaker rejects the request, or the request trips the endpoint's breaker, the same request is
+// sent to the next endpoint. Use NewFailover to create one.
+type Failover struct {
+	client    *http.Client
+	endpoints []Endpoint
+	opts      Opts
+}
+
+// NewFailover creates a Failover that sends requests using client to the endpoints, in order of preference.
+// opts apply to every endpoint: requests are retried and hedged against an endpoint, as configured, before failing
+// over to the next one.
+func NewFailover(client *http.Client, opts Opts, endpoints ...Endpoint) (*Failover, error) {
+	if len(endpoints) == 0 {
+		return nil, ErrNoEndpoints
+	}
+	parsed := make([]Endpoint, len(endpoints))
+	for i, endpoint := range endpoints {
+		baseURL, err := url.Parse(endpoint.BaseURL)
+		if err != nil {
+			return nil, err
+		}
+		parsed[i] = endpoint
+		parsed[i].baseURL = baseURL
+	}
+	return &Failover{
+		client:    client,
+		endpoints: parsed,
+		opts:      opts,
+	}, nil
+}
+
+// Do is like http.Client.Do, but sends the request to the first endpoint that accepts it.
+// The request's scheme and host are replaced by the endpoint's and its path is appended to the endpoint's path, so
+// requests are usually created with only a path, such as http.NewRequest(http.MethodGet, "/things/1", nil)
+func (f *Failover) Do(req *http.Request) (*http.Response, error) {
+	resp, _, err := f.DoWithEndpoint(req)
+	return resp, err
+}
+
+// DoWithEndpoint is like Do, but also reports which endpoint served the request.
+// servedBy is nil if every endpoint's breaker rejected the request.
+//
+// Requests with a body can only be sent to more than one endpoint if the request has GetBody set, which
+// http.NewRequest does for common in-memory bodies. If it's not set, the response from the first endpoint that
+// sent the request is returned, even if it tripped the breaker.
+func (f *Failover) DoWithEndpoint(req *http.Request) (resp *http.Response, servedBy *Endpoint, err error) {
+	bodyConsumed := false
+	for i := range f.endpoints {
+		endpoint := &f.endpoints[i]
+		endpointReq, reqErr := endpointRequest(req, endpoint.baseURL, bodyConsumed)
+		if reqErr != nil {
+			return nil, nil, reqErr
+		}
+
+		var result outcome
+		resp, result, err = f.opts.retryOrHedge(endpoint.Breaker, endpointReq, f.client.Do)
+		if result == outcomeRejected {
+			if i == len(f.endpoints)-1 {
+				resp, err = f.opts.respond(endpoint.Breaker, endpointReq, resp, result, err)
+				return resp, nil, err
+			}
+			continue
+		}
+
+		bodyConsumed = true
+		if result == outcomeSent || i == len(f.endpoints)-1 || !canReplayBody(req) {
+			return resp, endpoint, err
+		}
+		// tripped, try the next endpoint
+		closeBody(resp)
+	}
+	// unreachable, the last endpoint always returns
+	return nil, nil, nil
+}
+
+// endpointRequest copies req, pointing it at baseURL. The body is re-created when it was already sent.
+func endpointRequest(req *http.Request, baseURL *url.URL, bodyConsumed bool) (*http.Request, error) {
+	endpointReq := req.Clone(req.Context())
+	rewriteURL(endpointReq, baseURL)
+	if bodyConsumed && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		endpointReq.Body = body
+	}
+	return endpointReq, nil
+}
+
+// rewriteURL points req at baseURL, keeping the path and query of the request
+func rewriteURL(req *http.Request, baseURL *url.URL) {
+	req.URL.Scheme = baseURL.Scheme
+	req.URL.Host = baseURL.Host
+	req.URL.User = baseURL.User
+	if baseURL.Path != "" {
+		joined := path.Join(baseURL.Path, req.URL.Path)
+		if strings.HasSuffix(req.URL.Path, "/") && !strings.HasSuffix(joined, "/") {
+			joined += "/"
+		}
+		req.URL.Path = joined
+		req.URL.RawPath = ""
+	}
+	// let the client derive the Host header from the new URL
+	req.Host = ""
+}
+
+// canReplayBody is true if the request can be sent more than once
+func canReplayBody(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// closeBody releases a response that will not be returned to the caller
+func closeBody(resp *http.Response) {
+	if resp != nil && resp.Body != nil {
+		_ = resp.Body.Close()
+	}
+}
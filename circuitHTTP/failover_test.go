@@ -0,0 +1,158 @@
+package circuitHTTP_test
+
+import (
+	"bytes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/wojnosystems/go-circuit-breaker/circuitHTTP"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"github.com/wojnosystems/go-circuit-breaker/twoStateCircuit"
+	"net/http"
+	"time"
+)
+
+var _ = Describe("Failover", func() {
+	var (
+		primary          *ghttp.Server
+		secondary        *ghttp.Server
+		primaryBreaker   *twoStateCircuit.Breaker
+		secondaryBreaker *twoStateCircuit.Breaker
+		subject          *circuitHTTP.Failover
+		opts             circuitHTTP.Opts
+	)
+	BeforeEach(func() {
+		primary = ghttp.NewServer()
+		secondary = ghttp.NewServer()
+		primaryBreaker = twoStateCircuit.New(twoStateCircuit.Opts{OpenDuration: 1 * time.Hour})
+		secondaryBreaker = twoStateCircuit.New(twoStateCircuit.Opts{OpenDuration: 1 * time.Hour})
+		opts = circuitHTTP.Opts{}
+	})
+	JustBeforeEach(func() {
+		var err error
+		subject, err = circuitHTTP.NewFailover(http.DefaultClient, opts,
+			circuitHTTP.Endpoint{BaseURL: primary.URL() + "/api", Breaker: primaryBreaker},
+			circuitHTTP.Endpoint{BaseURL: secondary.URL() + "/api", Breaker: secondaryBreaker},
+		)
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+	AfterEach(func() {
+		primary.Close()
+		secondary.Close()
+	})
+	It("requires endpoints", func() {
+		_, err := circuitHTTP.NewFailover(http.DefaultClient, circuitHTTP.Opts{})
+		Expect(err).Should(Equal(circuitHTTP.ErrNoEndpoints))
+	})
+	When("the primary is healthy", func() {
+		BeforeEach(func() {
+			primary.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest(http.MethodGet, "/api/things/1", "expand=true"),
+				ghttp.RespondWith(http.StatusOK, nil),
+			))
+		})
+		It("is served by the primary", func() {
+			req, _ := http.NewRequest(http.MethodGet, "/things/1?expand=true", nil)
+			resp, servedBy, err := subject.DoWithEndpoint(req)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(resp.StatusCode).Should(Equal(http.StatusOK))
+			Expect(servedBy.BaseURL).Should(Equal(primary.URL() + "/api"))
+			Expect(secondary.ReceivedRequests()).Should(BeEmpty())
+		})
+	})
+	When("the primary's breaker is open", func() {
+		BeforeEach(func() {
+			_ = primaryBreaker.Use(func() error {
+				return trippingError
+			})
+			secondary.AppendHandlers(ghttp.RespondWith(http.StatusOK, nil))
+		})
+		It("is served by the secondary", func() {
+			req, _ := http.NewRequest(http.MethodGet, "/things/1", nil)
+			resp, servedBy, err := subject.DoWithEndpoint(req)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(resp.StatusCode).Should(Equal(http.StatusOK))
+			Expect(servedBy.BaseURL).Should(Equal(secondary.URL() + "/api"))
+			Expect(primary.ReceivedRequests()).Should(BeEmpty())
+		})
+	})
+	When("the primary trips", func() {
+		BeforeEach(func() {
+			primary.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyBody([]byte("payload")),
+				ghttp.RespondWith(http.StatusBadGateway, nil),
+			))
+			secondary.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyBody([]byte("payload")),
+				ghttp.RespondWith(http.StatusCreated, nil),
+			))
+		})
+		It("replays the body to the secondary", func() {
+			req, _ := http.NewRequest(http.MethodPost, "/things", bytes.NewBufferString("payload"))
+			resp, servedBy, err := subject.DoWithEndpoint(req)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(resp.StatusCode).Should(Equal(http.StatusCreated))
+			Expect(servedBy.BaseURL).Should(Equal(secondary.URL() + "/api"))
+		})
+		It("does not replay bodies that cannot be re-created", func() {
+			req, _ := http.NewRequest(http.MethodPost, "/things", bytes.NewBufferString("payload"))
+			req.GetBody = nil
+			resp, servedBy, _ := subject.DoWithEndpoint(req)
+			Expect(resp.StatusCode).Should(Equal(http.StatusBadGateway))
+			Expect(servedBy.BaseURL).Should(Equal(primary.URL() + "/api"))
+			Expect(secondary.ReceivedRequests()).Should(BeEmpty())
+		})
+	})
+	When("retrying", func() {
+		BeforeEach(func() {
+			primaryBreaker = twoStateCircuit.New(twoStateCircuit.Opts{
+				OpenDuration: 1 * time.Hour,
+				TripDecider: func(_ *tripping.Error) bool {
+					return false
+				},
+			})
+			opts.Retrier = circuitHTTP.NewRetrier(circuitHTTP.RetryOpts{
+				InitialBackoff: 1 * time.Millisecond,
+			})
+			primary.AppendHandlers(
+				ghttp.RespondWith(http.StatusBadGateway, nil),
+				ghttp.RespondWith(http.StatusOK, nil),
+			)
+		})
+		It("retries the endpoint before failing over", func() {
+			req, _ := http.NewRequest(http.MethodGet, "/things/1", nil)
+			resp, servedBy, err := subject.DoWithEndpoint(req)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(resp.StatusCode).Should(Equal(http.StatusOK))
+			Expect(servedBy.BaseURL).Should(Equal(primary.URL() + "/api"))
+			Expect(secondary.ReceivedRequests()).Should(BeEmpty())
+		})
+	})
+	When("every breaker is open", func() {
+		BeforeEach(func() {
+			for _, breaker := range []*twoStateCircuit.Breaker{primaryBreaker, secondaryBreaker} {
+				_ = breaker.Use(func() error {
+					return trippingError
+				})
+			}
+		})
+		It("returns the last breaker's error", func() {
+			req, _ := http.NewRequest(http.MethodGet, "/things/1", nil)
+			resp, servedBy, err := subject.DoWithEndpoint(req)
+			Expect(err).Should(Equal(trippingError.Err))
+			Expect(resp).Should(BeNil())
+			Expect(servedBy).Should(BeNil())
+		})
+		When("responding when open", func() {
+			BeforeEach(func() {
+				opts.RespondWhenOpen = true
+			})
+			It("synthesizes a response", func() {
+				req, _ := http.NewRequest(http.MethodGet, "/things/1", nil)
+				resp, err := subject.Do(req)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(circuitHTTP.IsRejection(resp)).Should(BeTrue())
+			})
+		})
+	})
+})
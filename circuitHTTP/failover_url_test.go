@@ -0,0 +1,49 @@
+package circuitHTTP
+
+import (
+	. "github.com/onsi/gomega"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func Test_rewriteURL(t *testing.T) {
+	cases := map[string]struct {
+		baseURL  string
+		request  string
+		expected string
+	}{
+		"host only": {
+			baseURL:  "https://example.com",
+			request:  "/things/1?a=b",
+			expected: "https://example.com/things/1?a=b",
+		},
+		"path prefix": {
+			baseURL:  "https://example.com/api/",
+			request:  "/things/1",
+			expected: "https://example.com/api/things/1",
+		},
+		"replaces existing host": {
+			baseURL:  "http://secondary.example.com:8080",
+			request:  "https://primary.example.com/things",
+			expected: "http://secondary.example.com:8080/things",
+		},
+		"keeps trailing slash": {
+			baseURL:  "https://example.com/api",
+			request:  "/things/",
+			expected: "https://example.com/api/things/",
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			baseURL, err := url.Parse(dt.baseURL)
+			g.Expect(err).ShouldNot(HaveOccurred())
+			req, err := http.NewRequest(http.MethodGet, dt.request, nil)
+			g.Expect(err).ShouldNot(HaveOccurred())
+			rewriteURL(req, baseURL)
+			g.Expect(req.URL.String()).Should(Equal(dt.expected))
+			g.Expect(req.Host).Should(BeEmpty())
+		})
+	}
+}
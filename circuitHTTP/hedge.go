@@ -0,0 +1,260 @@
+package circuitHTTP
+
+import (
+	"context"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// hedgeLatencySamples is how many recent latencies are kept to compute the hedging percentile
+	hedgeLatencySamples = 128
+
+	// hedgeMinLatencySamples is how many latencies must be tracked before the percentile is trusted over Delay
+	hedgeMinLatencySamples = 20
+
+	// IdempotencyKeyHeader marks requests with non-idempotent methods as safe to send more than once
+	IdempotencyKeyHeader = "Idempotency-Key"
+)
+
+type HedgeOpts struct {
+	// Delay is how long to wait for the first attempt to respond before sending a hedge.
+	// Also used when Percentile is set, until enough latencies were tracked to compute it
+	Delay time.Duration
+
+	// Percentile, if greater than 0, sends a hedge once the first attempt has been outstanding longer than this
+	// percentile of recent latencies, such as 0.95 to hedge the slowest 5% of requests
+	Percentile float64
+
+	// Budget caps how many hedges are sent. Leave nil to hedge every slow request, which can double the load on a
+	// struggling backend
+	Budget *Budget
+}
+
+// Hedger sends a second attempt of slow, idempotent requests and uses whichever response arrives first.
+// Use NewHedger to create one and set it on Opts.Hedger.
+//
+// Hedges are sent through the breaker like any other attempt, so are never sent while the breaker is open.
+// The losing attempt is cancelled and is not counted against the breaker.
+type Hedger struct {
+	opts HedgeOpts
+
+	mu        sync.Mutex
+	latencies []time.Duration
+	next      int
+}
+
+// NewHedger creates a new Hedger
+func NewHedger(opts HedgeOpts) *Hedger {
+	return &Hedger{
+		opts:      opts,
+		latencies: make([]time.Duration, 0, hedgeLatencySamples),
+	}
+}
+
+// hedgeAttempt is one of the attempts sent by the Hedger. The result is only set once the attempt is delivered
+type hedgeAttempt struct {
+	cancel context.CancelFunc
+
+	// lost is set to 1 when the other attempt won, only access atomically
+	lost int32
+
+	resp   *http.Response
+	result outcome
+	err    error
+}
+
+// do sends req, hedging it if the first attempt is slow
+func (h *Hedger) do(o Opts, breaker Breaker, req *http.Request, send sender) (*http.Response, outcome, error) {
+	if h.opts.Budget != nil {
+		h.opts.Budget.Deposit()
+	}
+	results := make(chan *hedgeAttempt, 2)
+	start := time.Now()
+	attempts := []*hedgeAttempt{
+		h.send(o, breaker, req.Clone(req.Context()), send, results),
+	}
+	outstanding := 1
+
+	timer := time.NewTimer(h.delay())
+	defer timer.Stop()
+	hedgeAt := timer.C
+
+	var failed *hedgeAttempt
+	for outstanding > 0 {
+		select {
+		case <-hedgeAt:
+			hedgeAt = nil
+			if !h.shouldHedge(breaker) {
+				continue
+			}
+			hedgeReq, err := replayRequest(req)
+			if err != nil {
+				continue
+			}
+			attempts = append(attempts, h.send(o, breaker, hedgeReq, send, results))
+			outstanding++
+		case attempt := <-results:
+			outstanding--
+			if attempt.result == outcomeSent {
+				h.recordLatency(time.Since(start))
+				abandon(attempts, attempt, failed, results, outstanding)
+				return attempt.finish()
+			}
+			if failed == nil {
+				// keep waiting in case the other attempt succeeds
+				failed = attempt
+			} else {
+				attempt.discard()
+			}
+		}
+	}
+	return failed.finish()
+}
+
+// send starts an attempt in the background, delivering it to results once it completes
+func (h *Hedger) send(o Opts, breaker Breaker, req *http.Request, send sender, results chan<- *hedgeAttempt) *hedgeAttempt {
+	ctx, cancel := context.WithCancel(req.Context())
+	attempt := &hedgeAttempt{
+		cancel: cancel,
+	}
+	attemptOpts := o
+	// the TripDecider is replaced below, so the default cost is decided by the original one
+	attemptOpts.CostFunc = o.costFunc()
+	attemptOpts.TripDecider = func(resp *http.Response, err error) error {
+		if atomic.LoadInt32(&attempt.lost) == 1 {
+			// cancelled because the other attempt won, this says nothing about the backend's health
+			return tripping.Unrecorded(err)
+		}
+		return o.TripDecider.ConvertToTrippingErrIfShould(resp, err)
+	}
+	attemptReq := req.WithContext(ctx)
+	go func() {
+		attempt.resp, attempt.result, attempt.err = attemptOpts.attempt(breaker, attemptReq, send)
+		results <- attempt
+	}()
+	return attempt
+}
+
+// replayRequest copies req with a fresh body so it can be sent again
+func replayRequest(req *http.Request) (*http.Request, error) {
+	replay := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		replay.Body = body
+	}
+	return replay, nil
+}
+
+// abandon cancels every attempt other than the winner and releases them once they complete
+func abandon(attempts []*hedgeAttempt, winner *hedgeAttempt, failed *hedgeAttempt, results <-chan *hedgeAttempt, outstanding int) {
+	if failed != nil {
+		failed.discard()
+	}
+	for _, attempt := range attempts {
+		if attempt != winner && attempt != failed {
+			// mark as lost before cancelling so the cancellation is not counted against the breaker
+			atomic.StoreInt32(&attempt.lost, 1)
+			attempt.cancel()
+		}
+	}
+	if outstanding == 0 {
+		return
+	}
+	go func() {
+		for i := 0; i < outstanding; i++ {
+			(<-results).discard()
+		}
+	}()
+}
+
+// shouldHedge is true if a hedge may be sent now. Hedges are never sent while the breaker is open
+func (h *Hedger) shouldHedge(breaker Breaker) bool {
+	if describer, ok := breaker.(StateDescriber); ok && describer.RetryAfter() > 0 {
+		return false
+	}
+	return h.opts.Budget == nil || h.opts.Budget.Withdraw()
+}
+
+// delay is how long to wait before sending a hedge
+func (h *Hedger) delay() time.Duration {
+	if h.opts.Percentile <= 0 {
+		return h.opts.Delay
+	}
+	h.mu.Lock()
+	if len(h.latencies) < hedgeMinLatencySamples {
+		h.mu.Unlock()
+		return h.opts.Delay
+	}
+	sorted := make([]time.Duration, len(h.latencies))
+	copy(sorted, h.latencies)
+	h.mu.Unlock()
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	index := int(h.opts.Percentile * float64(len(sorted)))
+	if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+	return sorted[index]
+}
+
+// recordLatency tracks how long a successful request took
+func (h *Hedger) recordLatency(latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.latencies) < hedgeLatencySamples {
+		h.latencies = append(h.latencies, latency)
+		return
+	}
+	h.latencies[h.next] = latency
+	h.next = (h.next + 1) % hedgeLatencySamples
+}
+
+// finish returns the attempt to the caller. The attempt's context is cancelled once its body is closed
+func (a *hedgeAttempt) finish() (*http.Response, outcome, error) {
+	if a.resp == nil || a.resp.Body == nil {
+		a.cancel()
+	} else {
+		a.resp.Body = &cancelOnClose{ReadCloser: a.resp.Body, cancel: a.cancel}
+	}
+	return a.resp, a.result, a.err
+}
+
+// discard releases an attempt that will not be returned to the caller
+func (a *hedgeAttempt) discard() {
+	a.cancel()
+	closeBody(a.resp)
+}
+
+// cancelOnClose cancels the attempt's context when the body is closed, releasing its resources
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the context
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// isIdempotent is true if the request may safely be sent more than once
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return req.Header.Get(IdempotencyKeyHeader) != ""
+	}
+}
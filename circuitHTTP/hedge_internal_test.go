@@ -0,0 +1,77 @@
+package circuitHTTP
+
+import (
+	. "github.com/onsi/gomega"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func Test_isIdempotent(t *testing.T) {
+	cases := map[string]struct {
+		method         string
+		idempotencyKey string
+		expected       bool
+	}{
+		"get": {
+			method:   http.MethodGet,
+			expected: true,
+		},
+		"put": {
+			method:   http.MethodPut,
+			expected: true,
+		},
+		"post": {
+			method: http.MethodPost,
+		},
+		"post with idempotency key": {
+			method:         http.MethodPost,
+			idempotencyKey: "abc",
+			expected:       true,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			req, _ := http.NewRequest(dt.method, "https://example.com", nil)
+			if dt.idempotencyKey != "" {
+				req.Header.Set(IdempotencyKeyHeader, dt.idempotencyKey)
+			}
+			g.Expect(isIdempotent(req)).Should(Equal(dt.expected))
+		})
+	}
+}
+
+func TestHedger_delay(t *testing.T) {
+	cases := map[string]struct {
+		opts      HedgeOpts
+		latencies int
+		expected  time.Duration
+	}{
+		"fixed delay": {
+			opts:      HedgeOpts{Delay: 50 * time.Millisecond},
+			latencies: 100,
+			expected:  50 * time.Millisecond,
+		},
+		"percentile without enough samples": {
+			opts:      HedgeOpts{Delay: 50 * time.Millisecond, Percentile: 0.9},
+			latencies: hedgeMinLatencySamples - 1,
+			expected:  50 * time.Millisecond,
+		},
+		"percentile": {
+			opts:      HedgeOpts{Delay: 50 * time.Millisecond, Percentile: 0.9},
+			latencies: 100,
+			expected:  91 * time.Millisecond,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			subject := NewHedger(dt.opts)
+			for i := 1; i <= dt.latencies; i++ {
+				subject.recordLatency(time.Duration(i) * time.Millisecond)
+			}
+			g.Expect(subject.delay()).Should(Equal(dt.expected))
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package circuitHTTP_test
+
+import (
+	"errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/wojnosystems/go-circuit-breaker/circuitHTTP"
+	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit"
+	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit/state"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"github.com/wojnosystems/go-circuit-breaker/twoStateCircuit"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// respondSlowly responds with body after a moment, unless the client gives up first
+func respondSlowly(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case <-req.Context().Done():
+		case <-time.After(300 * time.Millisecond):
+			_, _ = w.Write([]byte(body))
+		}
+	}
+}
+
+func readBody(resp *http.Response) string {
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body, err := ioutil.ReadAll(resp.Body)
+	Expect(err).ShouldNot(HaveOccurred())
+	return string(body)
+}
+
+var _ = Describe("Hedger", func() {
+	var (
+		server    *ghttp.Server
+		breaker   *twoStateCircuit.Breaker
+		hedgeOpts circuitHTTP.HedgeOpts
+		client    *circuitHTTP.Client
+	)
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		server.AllowUnhandledRequests = true
+		breaker = twoStateCircuit.New(twoStateCircuit.Opts{
+			OpenDuration: 1 * time.Hour,
+		})
+		hedgeOpts = circuitHTTP.HedgeOpts{
+			Delay: 20 * time.Millisecond,
+		}
+		server.AppendHandlers(
+			respondSlowly("slow"),
+			ghttp.RespondWith(http.StatusOK, "fast"),
+		)
+	})
+	JustBeforeEach(func() {
+		client = circuitHTTP.NewWithOpts(breaker, http.DefaultClient, circuitHTTP.Opts{
+			Hedger: circuitHTTP.NewHedger(hedgeOpts),
+		})
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+	When("the first attempt is slow", func() {
+		It("uses the hedge's response", func() {
+			resp, err := client.Get(server.URL())
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(readBody(resp)).Should(Equal("fast"))
+			Expect(server.ReceivedRequests()).Should(HaveLen(2))
+		})
+		It("does not count the cancelled attempt against the breaker", func() {
+			resp, _ := client.Get(server.URL())
+			_ = resp.Body.Close()
+			Consistently(breaker.CircuitState, 100*time.Millisecond).Should(Equal("Closed"))
+		})
+	})
+	When("the request is not idempotent", func() {
+		It("does not hedge", func() {
+			resp, err := client.Post(server.URL(), "text/plain", strings.NewReader("thing"))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(readBody(resp)).Should(Equal("slow"))
+			Expect(server.ReceivedRequests()).Should(HaveLen(1))
+		})
+		It("hedges when it has an idempotency key", func() {
+			req, _ := http.NewRequest(http.MethodPost, server.URL(), strings.NewReader("thing"))
+			req.Header.Set(circuitHTTP.IdempotencyKeyHeader, "abc")
+			resp, err := client.Do(req)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(readBody(resp)).Should(Equal("fast"))
+		})
+	})
+	When("the budget is exhausted", func() {
+		BeforeEach(func() {
+			hedgeOpts.Budget = circuitHTTP.NewBudget(circuitHTTP.BudgetOpts{
+				Ratio:    0.1,
+				Capacity: 1,
+			})
+		})
+		It("does not hedge", func() {
+			resp, err := client.Get(server.URL())
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(readBody(resp)).Should(Equal("slow"))
+			Expect(server.ReceivedRequests()).Should(HaveLen(1))
+		})
+	})
+	When("the breaker is open", func() {
+		BeforeEach(func() {
+			_ = breaker.Use(func() error {
+				return trippingError
+			})
+		})
+		It("sends nothing", func() {
+			_, err := client.Get(server.URL())
+			Expect(err).Should(Equal(trippingError.Err))
+			Expect(server.ReceivedRequests()).Should(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("Hedger with a half-open breaker", func() {
+	var (
+		server  *ghttp.Server
+		breaker *threeStateCircuit.Breaker
+		client  *circuitHTTP.Client
+	)
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		server.AppendHandlers(
+			respondSlowly("slow"),
+			ghttp.RespondWith(http.StatusOK, "fast"),
+		)
+		breaker = threeStateCircuit.New(threeStateCircuit.Opts{
+			OpenDuration:                       10 * time.Millisecond,
+			NumberOfSuccessesInHalfOpenToClose: 2,
+		})
+		_ = breaker.Use(func() error {
+			return tripping.New(errors.New("down"))
+		})
+		time.Sleep(20 * time.Millisecond)
+		client = circuitHTTP.NewWithOpts(breaker, http.DefaultClient, circuitHTTP.Opts{
+			Hedger: circuitHTTP.NewHedger(circuitHTTP.HedgeOpts{
+				Delay: 20 * time.Millisecond,
+			}),
+		})
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+	It("counts only the winning attempt as a success", func() {
+		resp, err := client.Get(server.URL())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(readBody(resp)).Should(Equal("fast"))
+		Consistently(func() threeStateCircuit.Snapshot {
+			return breaker.Snapshot()
+		}, 100*time.Millisecond).Should(And(
+			WithTransform(func(s threeStateCircuit.Snapshot) state.State {
+				return s.State
+			}, Equal(state.HalfOpen)),
+			WithTransform(func(s threeStateCircuit.Snapshot) uint64 {
+				return s.HalfOpenSuccesses
+			}, Equal(uint64(1))),
+		))
+	})
+})
@@ -0,0 +1,171 @@
+package circuitHTTP
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ClassifyServerResponse converts the status a handler responded with, and how long it took, into a tripping error
+// if the response should count against the breaker. Return nil if it should not.
+type ClassifyServerResponse func(statusCode int, latency time.Duration) error
+
+// ClassifyServerResponse functor that allows the default to be called without checking for nil in the Middleware
+func (c ClassifyServerResponse) ClassifyServerResponse(statusCode int, latency time.Duration) error {
+	if c != nil {
+		return c(statusCode, latency)
+	}
+	return defaultClassifyServerResponse(statusCode, latency)
+}
+
+// defaultClassifyServerResponse trips on every 5xx status, which usually means a dependency of the handler is failing
+func defaultClassifyServerResponse(statusCode int, _ time.Duration) error {
+	if statusCode >= http.StatusInternalServerError {
+		return tripping.New(fmt.Errorf("handler responded with %d %s", statusCode, http.StatusText(statusCode)))
+	}
+	return nil
+}
+
+// RouteKey returns the key of the breaker protecting the handler that will serve req, such as the route's pattern.
+// Keep the number of distinct keys small, a breaker is created for each one.
+type RouteKey func(req *http.Request) string
+
+// Middleware protects a handler with a breaker. Each response is classified with classifier, which may be nil to
+// trip on every 5xx status. While the breaker is open, requests are shed with a 503 Service Unavailable, a
+// Retry-After header and a problem details body without calling the handler.
+func Middleware(breaker Breaker, classifier ClassifyServerResponse) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			serveWithBreaker(breaker, classifier, next, w, req)
+		})
+	}
+}
+
+// KeyedMiddleware is like Middleware, but each route has its own breaker so a single failing route does not shed
+// requests for the others. newBreaker is called once, the first time each key is seen.
+func KeyedMiddleware(newBreaker func(key string) Breaker, routeKey RouteKey, classifier ClassifyServerResponse) func(next http.Handler) http.Handler {
+	breakers := &keyedBreakers{
+		newBreaker: newBreaker,
+		breakers:   make(map[string]Breaker),
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			serveWithBreaker(breakers.get(routeKey(req)), classifier, next, w, req)
+		})
+	}
+}
+
+// serveWithBreaker calls next through the breaker, or sheds the request if the breaker rejects it
+func serveWithBreaker(breaker Breaker, classifier ClassifyServerResponse, next http.Handler, w http.ResponseWriter, req *http.Request) {
+	called := false
+	_ = useBreaker(req.Context(), breaker, func() error {
+		called = true
+		recorder := &statusRecorder{ResponseWriter: w}
+		start := time.Now()
+		next.ServeHTTP(recorder, req)
+		if recorder.hijacked {
+			// the handler took over the connection, such as for a WebSocket, there is no response to classify
+			return nil
+		}
+		return classifier.ClassifyServerResponse(recorder.statusCode(), time.Since(start))
+	})
+	if !called {
+		// the breaker's error describes our dependencies, don't leak it to callers
+		writeOpenCircuitResponse(w, breaker, nil)
+	}
+}
+
+// keyedBreakers lazily creates a breaker for each key
+type keyedBreakers struct {
+	newBreaker func(key string) Breaker
+	mu         sync.RWMutex
+	breakers   map[string]Breaker
+}
+
+// get returns the breaker for the key, creating it if it does not exist
+func (k *keyedBreakers) get(key string) Breaker {
+	k.mu.RLock()
+	breaker, ok := k.breakers[key]
+	k.mu.RUnlock()
+	if ok {
+		return breaker
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if breaker, ok = k.breakers[key]; !ok {
+		breaker = k.newBreaker(key)
+		k.breakers[key] = breaker
+	}
+	return breaker
+}
+
+// statusRecorder remembers the status code the handler responded with
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+
+	// hijacked is true once the handler took over the connection
+	hijacked bool
+}
+
+// WriteHeader records the status before sending it
+func (r *statusRecorder) WriteHeader(statusCode int) {
+	if r.status == 0 {
+		r.status = statusCode
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write sends the body, implicitly responding with 200 OK if the handler did not call WriteHeader
+func (r *statusRecorder) Write(body []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(body)
+}
+
+// Flush allows streaming handlers to flush through the recorder
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack allows handlers that upgrade the connection, such as to a WebSocket, to take it over through the recorder
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err == nil {
+		r.hijacked = true
+	}
+	return conn, rw, err
+}
+
+// Push allows HTTP/2 handlers to push resources through the recorder
+func (r *statusRecorder) Push(target string, opts *http.PushOptions) error {
+	if pusher, ok := r.ResponseWriter.(http.Pusher); ok {
+		return pusher.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// Unwrap exposes the original http.ResponseWriter to http.ResponseController
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// statusCode is the status sent to the client. Handlers that never write respond with 200 OK
+func (r *statusRecorder) statusCode() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
@@ -0,0 +1,147 @@
+package circuitHTTP_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/circuitHTTP"
+	"github.com/wojnosystems/go-circuit-breaker/twoStateCircuit"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func respondWith(statusCode int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(statusCode)
+	})
+}
+
+func serve(handler http.Handler, path string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	return recorder
+}
+
+var _ = Describe("Middleware", func() {
+	var (
+		breaker *twoStateCircuit.Breaker
+	)
+	BeforeEach(func() {
+		breaker = twoStateCircuit.New(twoStateCircuit.Opts{
+			OpenDuration: 1 * time.Minute,
+		})
+	})
+	When("the handler succeeds", func() {
+		It("passes the response through", func() {
+			handler := circuitHTTP.Middleware(breaker, nil)(respondWith(http.StatusCreated))
+			Expect(serve(handler, "/").Code).Should(Equal(http.StatusCreated))
+			Expect(breaker.CircuitState()).Should(Equal("Closed"))
+		})
+		It("treats handlers that only write a body as 200 OK", func() {
+			handler := circuitHTTP.Middleware(breaker, func(statusCode int, _ time.Duration) error {
+				Expect(statusCode).Should(Equal(http.StatusOK))
+				return nil
+			})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("ok"))
+			}))
+			Expect(serve(handler, "/").Body.String()).Should(Equal("ok"))
+		})
+	})
+	When("the handler fails", func() {
+		var (
+			handler http.Handler
+		)
+		BeforeEach(func() {
+			handler = circuitHTTP.Middleware(breaker, nil)(respondWith(http.StatusInternalServerError))
+			Expect(serve(handler, "/").Code).Should(Equal(http.StatusInternalServerError))
+		})
+		It("opens the breaker", func() {
+			Expect(breaker.CircuitState()).Should(Equal("Open"))
+		})
+		It("sheds requests while open", func() {
+			resp := serve(handler, "/")
+			Expect(resp.Code).Should(Equal(http.StatusServiceUnavailable))
+			Expect(resp.Header().Get("Retry-After")).Should(Equal("60"))
+			Expect(resp.Header().Get(circuitHTTP.CircuitStateHeader)).Should(Equal("Open"))
+			Expect(resp.Body.String()).Should(MatchJSON(`{
+				"type": "about:blank",
+				"title": "Service Unavailable",
+				"status": 503,
+				"circuitState": "Open"
+			}`))
+		})
+	})
+	When("the classifier ignores the failure", func() {
+		It("does not open the breaker", func() {
+			handler := circuitHTTP.Middleware(breaker, func(_ int, _ time.Duration) error {
+				return nil
+			})(respondWith(http.StatusInternalServerError))
+			_ = serve(handler, "/")
+			Expect(breaker.CircuitState()).Should(Equal("Closed"))
+		})
+	})
+	When("the handler hijacks the connection", func() {
+		It("passes the connection through without classifying it", func() {
+			handler := circuitHTTP.Middleware(breaker, func(_ int, _ time.Duration) error {
+				defer GinkgoRecover()
+				Fail("hijacked connections have no response to classify")
+				return nil
+			})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				defer GinkgoRecover()
+				hijacker, ok := w.(http.Hijacker)
+				Expect(ok).Should(BeTrue())
+				conn, rw, err := hijacker.Hijack()
+				Expect(err).ShouldNot(HaveOccurred())
+				defer func() {
+					_ = conn.Close()
+				}()
+				_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: test\r\n\r\n")
+				_ = rw.Flush()
+			}))
+			server := httptest.NewServer(handler)
+			defer server.Close()
+
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			req.Header.Set("Connection", "Upgrade")
+			req.Header.Set("Upgrade", "test")
+			resp, err := http.DefaultClient.Do(req)
+			Expect(err).ShouldNot(HaveOccurred())
+			_ = resp.Body.Close()
+			Expect(resp.StatusCode).Should(Equal(http.StatusSwitchingProtocols))
+			Expect(breaker.CircuitState()).Should(Equal("Closed"))
+		})
+	})
+})
+
+var _ = Describe("KeyedMiddleware", func() {
+	var (
+		breakers map[string]*twoStateCircuit.Breaker
+		handler  http.Handler
+	)
+	BeforeEach(func() {
+		breakers = make(map[string]*twoStateCircuit.Breaker)
+		mux := http.NewServeMux()
+		mux.Handle("/broken", respondWith(http.StatusBadGateway))
+		mux.Handle("/working", respondWith(http.StatusOK))
+		handler = circuitHTTP.KeyedMiddleware(func(key string) circuitHTTP.Breaker {
+			breakers[key] = twoStateCircuit.New(twoStateCircuit.Opts{
+				OpenDuration: 1 * time.Minute,
+			})
+			return breakers[key]
+		}, func(req *http.Request) string {
+			return req.URL.Path
+		}, nil)(mux)
+	})
+	It("only sheds requests for the failing route", func() {
+		Expect(serve(handler, "/broken").Code).Should(Equal(http.StatusBadGateway))
+		Expect(serve(handler, "/broken").Code).Should(Equal(http.StatusServiceUnavailable))
+		Expect(serve(handler, "/working").Code).Should(Equal(http.StatusOK))
+	})
+	It("creates one breaker per route", func() {
+		_ = serve(handler, "/working")
+		_ = serve(handler, "/working")
+		_ = serve(handler, "/broken")
+		Expect(breakers).Should(HaveLen(2))
+	})
+})
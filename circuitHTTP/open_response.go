@@ -0,0 +1,172 @@
+package circuitHTTP
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// CircuitStateHeader is set on synthesized responses to the name of the state the breaker was in when it
+	// rejected the request
+	CircuitStateHeader = "X-Circuit-State"
+
+	problemContentType = "application/problem+json"
+
+	// defaultRejectedState is reported when the breaker cannot describe its own state: it rejected the request, so is
+	// most likely open
+	defaultRejectedState = "Open"
+
+	// rejectionHeader marks synthesized responses. It survives anything wrapping the response's body, such as
+	// http.Client when a Timeout is set
+	rejectionHeader = "X-Circuit-Rejected"
+)
+
+// rejectionToken is the value of the rejectionHeader. It's unique to this process, so responses synthesized by
+// another process and forwarded to this one, such as by a proxy, are not mistaken for rejections
+var rejectionToken = newRejectionToken()
+
+func newRejectionToken() string {
+	token := make([]byte, 16)
+	// fall back to a fixed marker in the unlikely event the system's random source fails
+	if _, err := rand.Read(token); err != nil {
+		return "rejected"
+	}
+	return hex.EncodeToString(token)
+}
+
+// rejectionCauseKey is the context key of the breaker's error on a synthesized response's Request
+type rejectionCauseKey struct{}
+
+// StateDescriber is optionally implemented by a Breaker. When implemented, synthesized responses report the state
+// the breaker is in and how long until it will attempt requests again.
+// twoStateCircuit.Breaker and threeStateCircuit.Breaker both implement this.
+type StateDescriber interface {
+	// CircuitState is the name of the state the breaker is in, such as "Open"
+	CircuitState() string
+
+	// RetryAfter is how long until the breaker will attempt requests again, or 0 if it does not know
+	RetryAfter() time.Duration
+}
+
+// problemDetails is the RFC 7807 body of a synthesized response
+type problemDetails struct {
+	Type         string `json:"type"`
+	Title        string `json:"title"`
+	Status       int    `json:"status"`
+	Detail       string `json:"detail,omitempty"`
+	CircuitState string `json:"circuitState"`
+}
+
+// openCircuitBody is the body of a synthesized response. It remembers the breaker's error so callers can recover it
+type openCircuitBody struct {
+	io.Reader
+	cause error
+}
+
+// Close satisfies io.ReadCloser, there is nothing to release
+func (b *openCircuitBody) Close() error {
+	return nil
+}
+
+// newOpenCircuitResponse creates a 503 response on behalf of a breaker that rejected req
+func newOpenCircuitResponse(req *http.Request, breaker Breaker, cause error) *http.Response {
+	header, body := openCircuitProblem(breaker, cause)
+	header.Set(rejectionHeader, rejectionToken)
+	if req != nil {
+		req = req.WithContext(context.WithValue(req.Context(), rejectionCauseKey{}, cause))
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(http.StatusServiceUnavailable) + " " + http.StatusText(http.StatusServiceUnavailable),
+		StatusCode:    http.StatusServiceUnavailable,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          &openCircuitBody{Reader: bytes.NewReader(body), cause: cause},
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// writeOpenCircuitResponse sends a 503 response on behalf of a breaker that rejected the request being served
+func writeOpenCircuitResponse(w http.ResponseWriter, breaker Breaker, cause error) {
+	header, body := openCircuitProblem(breaker, cause)
+	for key, values := range header {
+		w.Header()[key] = values
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusServiceUnavailable)
+	_, _ = w.Write(body)
+}
+
+// openCircuitProblem creates the headers and problem details body describing a rejection by breaker
+func openCircuitProblem(breaker Breaker, cause error) (header http.Header, body []byte) {
+	circuitState, retryAfter := describeBreaker(breaker)
+	problem := problemDetails{
+		Type:         "about:blank",
+		Title:        http.StatusText(http.StatusServiceUnavailable),
+		Status:       http.StatusServiceUnavailable,
+		CircuitState: circuitState,
+	}
+	if cause != nil {
+		problem.Detail = cause.Error()
+	}
+	// problemDetails only contains strings and ints, this cannot fail
+	body, _ = json.Marshal(problem)
+
+	header = make(http.Header)
+	header.Set("Content-Type", problemContentType)
+	header.Set("Retry-After", retryAfterSeconds(retryAfter))
+	header.Set(CircuitStateHeader, circuitState)
+	return
+}
+
+// describeBreaker returns the breaker's state and retry delay if the breaker is able to describe itself
+func describeBreaker(breaker Breaker) (circuitState string, retryAfter time.Duration) {
+	if describer, ok := breaker.(StateDescriber); ok {
+		return describer.CircuitState(), describer.RetryAfter()
+	}
+	return defaultRejectedState, 0
+}
+
+// retryAfterSeconds formats the delay for the Retry-After header. Partial seconds are rounded up and callers are
+// always asked to wait at least 1 second so they do not immediately retry against a breaker that just rejected them.
+func retryAfterSeconds(retryAfter time.Duration) string {
+	seconds := int64(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.FormatInt(seconds, 10)
+}
+
+// IsRejection returns true if resp was synthesized because the breaker rejected the request, rather than being sent
+// by the server
+func IsRejection(resp *http.Response) bool {
+	return resp != nil && resp.Header.Get(rejectionHeader) == rejectionToken
+}
+
+// RejectionCause returns the breaker's error for a synthesized response, which is the same error the breaker would
+// have returned had RespondWhenOpen not been set. Returns nil if resp was sent by the server.
+func RejectionCause(resp *http.Response) error {
+	if !IsRejection(resp) {
+		return nil
+	}
+	if body, ok := resp.Body.(*openCircuitBody); ok {
+		return body.cause
+	}
+	// the body was wrapped, such as by http.Client
+	if resp.Request != nil {
+		if cause, ok := resp.Request.Context().Value(rejectionCauseKey{}).(error); ok {
+			return cause
+		}
+	}
+	return nil
+}
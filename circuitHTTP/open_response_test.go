@@ -0,0 +1,33 @@
+package circuitHTTP
+
+import (
+	. "github.com/onsi/gomega"
+	"testing"
+	"time"
+)
+
+func Test_retryAfterSeconds(t *testing.T) {
+	cases := map[string]struct {
+		input    time.Duration
+		expected string
+	}{
+		"unknown waits at least a second": {
+			input:    0,
+			expected: "1",
+		},
+		"partial seconds round up": {
+			input:    1500 * time.Millisecond,
+			expected: "2",
+		},
+		"whole seconds": {
+			input:    30 * time.Second,
+			expected: "30",
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(retryAfterSeconds(dt.input)).Should(Equal(dt.expected))
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package circuitHTTP
+
+import (
+	"context"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"net/http"
+	"time"
+)
+
+// Opts customizes how a Client or Transport uses its breaker
+type Opts struct {
+	// TripDecider converts responses and errors into tripping errors.
+	// Leave nil to use the default, which trips on errors and statuses that usually indicate an outage or rate limit.
+	TripDecider ConvertToTrippingErrIfShould
+
+	// CostFunc, if set, computes the cost of the tripping errors returned by the TripDecider from how long the request
+	// took. Leave nil to use DefaultCost with the default TripDecider, the costs of a custom TripDecider are kept.
+	// A CostFunc set on the breaker itself replaces this cost.
+	CostFunc tripping.CostFunc
+
+	// RespondWhenOpen, if true, returns a synthesized 503 Service Unavailable response with a nil error whenever
+	// the breaker rejects a request, instead of returning a nil response and the breaker's last error.
+	// Use IsRejection to tell these responses apart from ones sent by the server.
+	RespondWhenOpen bool
+
+	// Hedger, if set, sends a second attempt of idempotent requests that are slow to respond
+	Hedger *Hedger
+
+	// Retrier, if set, retries requests that trip the breaker. Retries stop as soon as the breaker rejects a request
+	Retrier *Retrier
+
+	// PriorityHeader, if set, is the request header naming the request's priority: Critical, Default or Sheddable.
+	// Breakers implementing ContextBreaker admit higher priority requests first while they recover.
+	// Without the header, the priority set on the request's context using priority.WithPriority is used
+	PriorityHeader string
+}
+
+// sender sends a request, usually http.Client.Do or http.RoundTripper.RoundTrip
+type sender func(req *http.Request) (*http.Response, error)
+
+// outcome is what happened to a single attempt to send a request through a breaker
+type outcome uint8
+
+const (
+	// outcomeSent means the request was sent and the result did not trip the breaker
+	outcomeSent outcome = iota
+	// outcomeTripped means the request was sent and the breaker recorded the result as a failure
+	outcomeTripped
+	// outcomeRejected means the breaker refused to send the request at all
+	outcomeRejected
+)
+
+// router is implemented by breakers that choose where each request is sent, such as a balancer choosing a backend.
+// callback is called with the request rewritten for the chosen destination and the breaker protecting it
+type router interface {
+	route(ctx context.Context, req *http.Request, callback func(routed *http.Request, breaker Breaker) error) error
+}
+
+// do sends the request through the breaker, synthesizing a response on rejection if configured to do so
+func (o Opts) do(breaker Breaker, req *http.Request, send sender) (*http.Response, error) {
+	resp, result, err := o.retryOrHedge(breaker, req, send)
+	return o.respond(breaker, req, resp, result, err)
+}
+
+// retryOrHedge sends the request through the breaker, retrying and hedging it if configured to
+func (o Opts) retryOrHedge(breaker Breaker, req *http.Request, send sender) (*http.Response, outcome, error) {
+	if o.Retrier != nil {
+		return o.Retrier.do(o, breaker, req, send)
+	}
+	return o.hedgeOrAttempt(breaker, req, send)
+}
+
+// hedgeOrAttempt hedges the request if configured to and the request is safe to send more than once, otherwise it's
+// attempted once
+func (o Opts) hedgeOrAttempt(breaker Breaker, req *http.Request, send sender) (*http.Response, outcome, error) {
+	if o.Hedger != nil && isIdempotent(req) && canReplayBody(req) {
+		return o.Hedger.do(o, breaker, req, send)
+	}
+	return o.attempt(breaker, req, send)
+}
+
+// respond synthesizes a response for rejected attempts if configured to do so
+func (o Opts) respond(breaker Breaker, req *http.Request, resp *http.Response, result outcome, err error) (*http.Response, error) {
+	if result == outcomeRejected && o.RespondWhenOpen {
+		return newOpenCircuitResponse(req, breaker, err), nil
+	}
+	return resp, err
+}
+
+// attempt sends the request through the breaker exactly once.
+// When the breaker refuses to send the request, result is outcomeRejected and err is the breaker's error.
+func (o Opts) attempt(breaker Breaker, req *http.Request, send sender) (resp *http.Response, result outcome, err error) {
+	result = outcomeRejected
+	sendRequest := func(sent *http.Request, sentThrough Breaker) error {
+		var sendErr error
+		sentAt := time.Now()
+		resp, sendErr = send(sent)
+		converted := o.weigh(o.TripDecider.ConvertToTrippingErrIfShould(resp, sendErr), time.Since(sentAt))
+		result = outcomeSent
+		if countsAgainst(sentThrough, converted) {
+			result = outcomeTripped
+		}
+		return converted
+	}
+	ctx := o.priorityContext(req)
+	if r, ok := breaker.(router); ok {
+		err = r.route(ctx, req, sendRequest)
+	} else {
+		err = useBreaker(ctx, breaker, func() error {
+			return sendRequest(req, breaker)
+		})
+	}
+	return resp, result, err
+}
+
+// costFunc is the CostFunc if set, DefaultCost with the default TripDecider, otherwise nil to keep the costs as they are
+func (o Opts) costFunc() tripping.CostFunc {
+	if o.CostFunc != nil {
+		return o.CostFunc
+	}
+	if o.TripDecider == nil {
+		return DefaultCost
+	}
+	return nil
+}
+
+// weigh sets the cost of a tripping error from how long the request took
+func (o Opts) weigh(err error, latency time.Duration) error {
+	costFunc := o.costFunc()
+	trippingErr, ok := err.(*tripping.Error)
+	if costFunc == nil || !ok {
+		return err
+	}
+	return costFunc.Weigh(trippingErr, latency)
+}
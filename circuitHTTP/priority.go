@@ -0,0 +1,36 @@
+package circuitHTTP
+
+import (
+	"context"
+	"github.com/wojnosystems/go-circuit-breaker/priority"
+	"net/http"
+)
+
+// ContextBreaker is optionally implemented by a Breaker. When implemented, requests are sent with their context so the
+// breaker can admit higher priority requests first while it recovers, see priority.WithPriority.
+// threeStateCircuit.Breaker and adaptiveThrottle.Throttle both implement this.
+type ContextBreaker interface {
+	Breaker
+	UseContext(ctx context.Context, callback func() error) error
+}
+
+// useBreaker calls the callback through the breaker, passing ctx along if the breaker accepts it
+func useBreaker(ctx context.Context, breaker Breaker, callback func() error) error {
+	if contextBreaker, ok := breaker.(ContextBreaker); ok {
+		return contextBreaker.UseContext(ctx, callback)
+	}
+	return breaker.Use(callback)
+}
+
+// priorityContext returns the request's context, carrying the priority from the PriorityHeader if it names one
+func (o Opts) priorityContext(req *http.Request) context.Context {
+	if o.PriorityHeader == "" {
+		return req.Context()
+	}
+	p, err := priority.ParsePriority(req.Header.Get(o.PriorityHeader))
+	if err != nil {
+		// missing or unknown, keep any priority already on the context
+		return req.Context()
+	}
+	return priority.WithPriority(req.Context(), p)
+}
@@ -0,0 +1,76 @@
+package circuitHTTP
+
+import (
+	"context"
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/priority"
+	"net/http"
+	"testing"
+)
+
+func TestOpts_priorityContext(t *testing.T) {
+	cases := map[string]struct {
+		header      string
+		headerValue string
+		ctx         context.Context
+		expected    priority.Priority
+	}{
+		"no header configured": {
+			headerValue: "Critical",
+			ctx:         context.Background(),
+			expected:    priority.Default,
+		},
+		"from the header": {
+			header:      "X-Priority",
+			headerValue: "Sheddable",
+			ctx:         context.Background(),
+			expected:    priority.Sheddable,
+		},
+		"header overrides the context": {
+			header:      "X-Priority",
+			headerValue: "Critical",
+			ctx:         priority.WithPriority(context.Background(), priority.Sheddable),
+			expected:    priority.Critical,
+		},
+		"unknown header keeps the context": {
+			header:      "X-Priority",
+			headerValue: "urgent",
+			ctx:         priority.WithPriority(context.Background(), priority.Sheddable),
+			expected:    priority.Sheddable,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			req, _ := http.NewRequestWithContext(dt.ctx, http.MethodGet, "http://localhost", nil)
+			req.Header.Set("X-Priority", dt.headerValue)
+			actual := priority.FromContext(Opts{PriorityHeader: dt.header}.priorityContext(req))
+			g.Expect(actual).Should(Equal(dt.expected))
+		})
+	}
+}
+
+// contextRecordingBreaker always attempts the callback, remembering the priority it was used with
+type contextRecordingBreaker struct {
+	priority priority.Priority
+}
+
+func (c *contextRecordingBreaker) Use(callback func() error) error {
+	return callback()
+}
+
+func (c *contextRecordingBreaker) UseContext(ctx context.Context, callback func() error) error {
+	c.priority = priority.FromContext(ctx)
+	return callback()
+}
+
+func TestOpts_attempt_passesPriority(t *testing.T) {
+	g := NewWithT(t)
+	breaker := &contextRecordingBreaker{}
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost", nil)
+	req.Header.Set("X-Priority", "Critical")
+	_, _, _ = Opts{PriorityHeader: "X-Priority"}.attempt(breaker, req, func(_ *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})
+	g.Expect(breaker.priority).Should(Equal(priority.Critical))
+}
@@ -0,0 +1,141 @@
+package circuitHTTP
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRetryMaxAttempts    = 3
+	defaultRetryInitialBackoff = 100 * time.Millisecond
+	defaultRetryMaxBackoff     = 10 * time.Second
+	defaultRetryMultiplier     = 2
+)
+
+type RetryOpts struct {
+	// MaxAttempts is the most times a request is sent, including the first attempt. Defaults to 3
+	MaxAttempts int
+
+	// InitialBackoff is the longest to wait before the first retry. Defaults to 100ms
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps how long to wait between any two attempts. Defaults to 10s
+	MaxBackoff time.Duration
+
+	// Multiplier grows the backoff after each retry. Defaults to 2
+	Multiplier float64
+
+	// Retryable returns true if req may be retried. Leave nil to only retry idempotent methods and requests with an
+	// Idempotency-Key header
+	Retryable func(req *http.Request) bool
+
+	// Budget caps how many retries are sent. Share a Budget between every client using the same breaker so retries
+	// cannot amplify an outage, such as BudgetOpts{Ratio: 0.1} for at most 10% extra load, saving up to 10 retries.
+	// Leave nil to always retry up to MaxAttempts
+	Budget *Budget
+
+	// RandomSource jitters the backoff. Leave nil to use math.Rand seeded with the current time.
+	// The source does not need to be thread-safe
+	RandomSource rand.Source
+}
+
+// Retrier retries requests that trip the breaker, waiting an exponentially growing, randomly jittered backoff between
+// attempts. Retries stop immediately when the breaker rejects a request, as the breaker is open and further attempts
+// would be rejected too. Use NewRetrier to create one and set it on Opts.Retrier
+type Retrier struct {
+	opts RetryOpts
+
+	randomMu sync.Mutex
+	random   *rand.Rand
+
+	// sleep waits for the backoff, allows the wait to be simulated
+	sleep func(ctx context.Context, backoff time.Duration) error
+}
+
+// NewRetrier creates a new Retrier
+func NewRetrier(opts RetryOpts) *Retrier {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultRetryMaxAttempts
+	}
+	if opts.InitialBackoff <= 0 {
+		opts.InitialBackoff = defaultRetryInitialBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = defaultRetryMaxBackoff
+	}
+	if opts.Multiplier < 1 {
+		opts.Multiplier = defaultRetryMultiplier
+	}
+	if opts.Retryable == nil {
+		opts.Retryable = isIdempotent
+	}
+	randomSource := opts.RandomSource
+	if randomSource == nil {
+		randomSource = rand.NewSource(time.Now().UnixNano())
+	}
+	return &Retrier{
+		opts:   opts,
+		random: rand.New(randomSource),
+		sleep:  sleepContext,
+	}
+}
+
+// do sends req, retrying while attempts trip the breaker and the budget allows
+func (r *Retrier) do(o Opts, breaker Breaker, req *http.Request, send sender) (resp *http.Response, result outcome, err error) {
+	if r.opts.Budget != nil {
+		r.opts.Budget.Deposit()
+	}
+	retryable := r.opts.Retryable(req) && canReplayBody(req)
+	attemptReq := req
+	for attempt := 1; ; attempt++ {
+		resp, result, err = o.hedgeOrAttempt(breaker, attemptReq, send)
+		if result != outcomeTripped || !retryable || attempt >= r.opts.MaxAttempts {
+			return
+		}
+		replay, replayErr := replayRequest(req)
+		if replayErr != nil {
+			return
+		}
+		if r.opts.Budget != nil && !r.opts.Budget.Withdraw() {
+			return
+		}
+		if sleepErr := r.sleep(req.Context(), r.backoff(attempt)); sleepErr != nil {
+			// the caller gave up, return what we have. The retry was never sent, so it costs nothing
+			if r.opts.Budget != nil {
+				r.opts.Budget.refund()
+			}
+			return
+		}
+		closeBody(resp)
+		attemptReq = replay
+	}
+}
+
+// backoff is how long to wait after the attempt failed. Uses "full jitter": a random duration up to the exponential
+// backoff, which spreads retries from many clients evenly over time
+func (r *Retrier) backoff(attempt int) time.Duration {
+	ceiling := float64(r.opts.InitialBackoff) * math.Pow(r.opts.Multiplier, float64(attempt-1))
+	if ceiling > float64(r.opts.MaxBackoff) {
+		ceiling = float64(r.opts.MaxBackoff)
+	}
+	r.randomMu.Lock()
+	jitter := r.random.Float64()
+	r.randomMu.Unlock()
+	return time.Duration(jitter * ceiling)
+}
+
+// sleepContext waits for the backoff, or until the context is done
+func sleepContext(ctx context.Context, backoff time.Duration) error {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
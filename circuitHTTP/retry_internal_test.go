@@ -0,0 +1,116 @@
+package circuitHTTP
+
+import (
+	"context"
+	"errors"
+	. "github.com/onsi/gomega"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// randSrcAlwaysMax makes every jittered backoff as long as possible
+type randSrcAlwaysMax struct {
+}
+
+func (r *randSrcAlwaysMax) Int63() int64 {
+	return math.MaxInt64 - 512
+}
+
+func (r *randSrcAlwaysMax) Seed(_ int64) {
+}
+
+func TestRetrier_backoff(t *testing.T) {
+	cases := map[string]struct {
+		opts     RetryOpts
+		attempt  int
+		expected time.Duration
+	}{
+		"first retry": {
+			opts:     RetryOpts{InitialBackoff: 100 * time.Millisecond},
+			attempt:  1,
+			expected: 100 * time.Millisecond,
+		},
+		"grows exponentially": {
+			opts:     RetryOpts{InitialBackoff: 100 * time.Millisecond},
+			attempt:  3,
+			expected: 400 * time.Millisecond,
+		},
+		"capped": {
+			opts:     RetryOpts{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond},
+			attempt:  3,
+			expected: 300 * time.Millisecond,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			dt.opts.RandomSource = &randSrcAlwaysMax{}
+			subject := NewRetrier(dt.opts)
+			g.Expect(subject.backoff(dt.attempt)).Should(BeNumerically("~", dt.expected, 1*time.Millisecond))
+		})
+	}
+}
+
+func TestRetrier_backoffIsJittered(t *testing.T) {
+	g := NewWithT(t)
+	subject := NewRetrier(RetryOpts{
+		InitialBackoff: 1 * time.Second,
+		RandomSource:   rand.NewSource(1),
+	})
+	first := subject.backoff(1)
+	second := subject.backoff(1)
+	g.Expect(first).ShouldNot(Equal(second))
+	g.Expect(first).Should(BeNumerically("<=", 1*time.Second))
+	g.Expect(second).Should(BeNumerically("<=", 1*time.Second))
+}
+
+// passThroughBreaker always calls the callback
+type passThroughBreaker struct {
+}
+
+func (p *passThroughBreaker) Use(callback func() error) error {
+	return callback()
+}
+
+func TestRetrier_do_BudgetOnlySpentOnSentRetries(t *testing.T) {
+	cases := map[string]struct {
+		getBody  func() (io.ReadCloser, error)
+		sleepErr error
+	}{
+		"body cannot be replayed": {
+			getBody: func() (io.ReadCloser, error) {
+				return nil, errors.New("cannot replay")
+			},
+		},
+		"caller gave up while backing off": {
+			getBody: func() (io.ReadCloser, error) {
+				return io.NopCloser(strings.NewReader("body")), nil
+			},
+			sleepErr: context.Canceled,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			budget := NewBudget(BudgetOpts{InitialTokens: 1})
+			subject := NewRetrier(RetryOpts{Budget: budget})
+			subject.sleep = func(_ context.Context, _ time.Duration) error {
+				return dt.sleepErr
+			}
+			req, _ := http.NewRequest(http.MethodPut, "http://localhost", strings.NewReader("body"))
+			req.GetBody = dt.getBody
+			sends := 0
+			_, _, _ = subject.do(Opts{}, &passThroughBreaker{}, req, func(_ *http.Request) (*http.Response, error) {
+				sends++
+				return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody}, nil
+			})
+			g.Expect(sends).Should(Equal(1))
+			g.Expect(budget.Tokens()).Should(Equal(1.0))
+		})
+	}
+}
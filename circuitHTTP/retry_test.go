@@ -0,0 +1,171 @@
+package circuitHTTP_test
+
+import (
+	"errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/wojnosystems/go-circuit-breaker/circuitHTTP"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"github.com/wojnosystems/go-circuit-breaker/twoStateCircuit"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var _ = Describe("Retrier", func() {
+	var (
+		server      *ghttp.Server
+		breaker     *twoStateCircuit.Breaker
+		retryOpts   circuitHTTP.RetryOpts
+		tripDecider circuitHTTP.ConvertToTrippingErrIfShould
+		client      *circuitHTTP.Client
+	)
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		tripDecider = nil
+		breaker = twoStateCircuit.New(twoStateCircuit.Opts{
+			OpenDuration: 1 * time.Hour,
+			TripDecider: func(_ *tripping.Error) bool {
+				return false
+			},
+		})
+		retryOpts = circuitHTTP.RetryOpts{
+			InitialBackoff: 1 * time.Millisecond,
+		}
+	})
+	JustBeforeEach(func() {
+		client = circuitHTTP.NewWithOpts(breaker, http.DefaultClient, circuitHTTP.Opts{
+			TripDecider: tripDecider,
+			Retrier:     circuitHTTP.NewRetrier(retryOpts),
+		})
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+	When("the first attempt trips", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusServiceUnavailable, nil),
+				ghttp.RespondWith(http.StatusOK, nil),
+			)
+		})
+		It("retries", func() {
+			resp, err := client.Get(server.URL())
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(resp.StatusCode).Should(Equal(http.StatusOK))
+			Expect(server.ReceivedRequests()).Should(HaveLen(2))
+		})
+	})
+	When("every attempt trips", func() {
+		BeforeEach(func() {
+			for i := 0; i < 5; i++ {
+				server.AppendHandlers(ghttp.RespondWith(http.StatusBadGateway, nil))
+			}
+		})
+		It("gives up after the maximum attempts", func() {
+			resp, err := client.Get(server.URL())
+			Expect(err).Should(HaveOccurred())
+			Expect(resp.StatusCode).Should(Equal(http.StatusBadGateway))
+			Expect(server.ReceivedRequests()).Should(HaveLen(3))
+		})
+		When("the budget is exhausted", func() {
+			BeforeEach(func() {
+				retryOpts.Budget = circuitHTTP.NewBudget(circuitHTTP.BudgetOpts{
+					Ratio:         0.1,
+					Capacity:      10,
+					InitialTokens: 1,
+				})
+			})
+			It("only retries what the budget allows", func() {
+				_, _ = client.Get(server.URL())
+				Expect(server.ReceivedRequests()).Should(HaveLen(2))
+			})
+		})
+		When("the breaker opens", func() {
+			BeforeEach(func() {
+				breaker = twoStateCircuit.New(twoStateCircuit.Opts{
+					OpenDuration: 1 * time.Hour,
+				})
+			})
+			It("stops retrying immediately", func() {
+				_, err := client.Get(server.URL())
+				Expect(err).Should(HaveOccurred())
+				Expect(server.ReceivedRequests()).Should(HaveLen(1))
+			})
+		})
+	})
+	When("the breaker's Classifier records an error that is not a tripping error", func() {
+		BeforeEach(func() {
+			errTeapot := errors.New("teapot")
+			tripDecider = func(resp *http.Response, err error) error {
+				if err == nil && resp.StatusCode == http.StatusTeapot {
+					return errTeapot
+				}
+				return err
+			}
+			breaker = twoStateCircuit.New(twoStateCircuit.Opts{
+				OpenDuration: 1 * time.Hour,
+				TripDecider: func(_ *tripping.Error) bool {
+					return false
+				},
+				Classifier: tripping.NewClassifier().Sentinel(errTeapot, tripping.Record, 1),
+			})
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusTeapot, nil),
+				ghttp.RespondWith(http.StatusOK, nil),
+			)
+		})
+		It("retries", func() {
+			resp, err := client.Get(server.URL())
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(resp.StatusCode).Should(Equal(http.StatusOK))
+			Expect(server.ReceivedRequests()).Should(HaveLen(2))
+		})
+	})
+	When("the budget was earned with the documented options", func() {
+		BeforeEach(func() {
+			retryOpts.Budget = circuitHTTP.NewBudget(circuitHTTP.BudgetOpts{Ratio: 0.1})
+			for i := 0; i < 10; i++ {
+				server.AppendHandlers(ghttp.RespondWith(http.StatusOK, nil))
+			}
+			for i := 0; i < 3; i++ {
+				server.AppendHandlers(ghttp.RespondWith(http.StatusBadGateway, nil))
+			}
+		})
+		It("retries once for every 10 requests", func() {
+			for i := 0; i < 10; i++ {
+				resp, err := client.Get(server.URL())
+				Expect(err).ShouldNot(HaveOccurred())
+				_ = resp.Body.Close()
+			}
+			_, _ = client.Get(server.URL())
+			Expect(server.ReceivedRequests()).Should(HaveLen(12))
+		})
+	})
+	When("the request is not idempotent", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusServiceUnavailable, nil),
+				ghttp.RespondWith(http.StatusOK, nil),
+			)
+		})
+		It("does not retry", func() {
+			_, _ = client.Post(server.URL(), "text/plain", strings.NewReader("thing"))
+			Expect(server.ReceivedRequests()).Should(HaveLen(1))
+		})
+		When("it has an idempotency key", func() {
+			It("retries with the same body", func() {
+				server.SetHandler(1, ghttp.CombineHandlers(
+					ghttp.VerifyBody([]byte("thing")),
+					ghttp.RespondWith(http.StatusOK, nil),
+				))
+				req, _ := http.NewRequest(http.MethodPost, server.URL(), strings.NewReader("thing"))
+				req.Header.Set(circuitHTTP.IdempotencyKeyHeader, "abc")
+				resp, err := client.Do(req)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(resp.StatusCode).Should(Equal(http.StatusOK))
+			})
+		})
+	})
+})
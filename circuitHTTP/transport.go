@@ -0,0 +1,35 @@
+package circuitHTTP
+
+import "net/http"
+
+// Transport is a http.RoundTripper with a circuit breaker inside. Every round trip that fails is counted in the breaker
+// Use NewTransport instead of using this struct as the breaker requires initialization
+type Transport struct {
+	base    http.RoundTripper
+	breaker Breaker
+	opts    Opts
+}
+
+// NewTransport wraps base with the breaker. If base is nil, http.DefaultTransport is used.
+// Install the result in any http.Client to protect code that does not use Client directly.
+func NewTransport(breaker Breaker, base http.RoundTripper, opts Opts) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{
+		base:    base,
+		breaker: breaker,
+		opts:    opts,
+	}
+}
+
+// RoundTrip satisfies http.RoundTripper
+// Responses with statuses that trip the breaker are still counted against it, but, as required by
+// http.RoundTripper, are returned without an error because the server did respond.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.opts.do(t.breaker, req, t.base.RoundTrip)
+	if resp != nil {
+		return resp, nil
+	}
+	return nil, err
+}
@@ -0,0 +1,113 @@
+package circuitHTTP_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/wojnosystems/go-circuit-breaker/circuitHTTP"
+	"github.com/wojnosystems/go-circuit-breaker/twoStateCircuit"
+	"net/http"
+	"time"
+)
+
+var _ = Describe("Transport", func() {
+	var (
+		server  *ghttp.Server
+		breaker *twoStateCircuit.Breaker
+	)
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		breaker = twoStateCircuit.New(twoStateCircuit.Opts{
+			OpenDuration: 1 * time.Hour,
+		})
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+	When("closed", func() {
+		var (
+			client *http.Client
+		)
+		BeforeEach(func() {
+			client = &http.Client{
+				Transport: circuitHTTP.NewTransport(breaker, nil, circuitHTTP.Opts{}),
+			}
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, nil),
+			)
+		})
+		It("succeeds", func() {
+			resp, err := client.Get(server.URL())
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(resp.StatusCode).Should(Equal(http.StatusOK))
+		})
+	})
+	When("open", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusInternalServerError, nil),
+			)
+		})
+		It("returns the tripping response without an error", func() {
+			client := &http.Client{
+				Transport: circuitHTTP.NewTransport(breaker, nil, circuitHTTP.Opts{}),
+			}
+			resp, err := client.Get(server.URL())
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(resp.StatusCode).Should(Equal(http.StatusInternalServerError))
+		})
+		It("returns an error", func() {
+			client := &http.Client{
+				Transport: circuitHTTP.NewTransport(breaker, nil, circuitHTTP.Opts{}),
+			}
+			_, _ = client.Get(server.URL())
+			_, err := client.Get(server.URL())
+			Expect(err).Should(HaveOccurred())
+		})
+		It("synthesizes a response when asked to", func() {
+			client := &http.Client{
+				Transport: circuitHTTP.NewTransport(breaker, nil, circuitHTTP.Opts{
+					RespondWhenOpen: true,
+				}),
+			}
+			_, _ = client.Get(server.URL())
+			resp, err := client.Get(server.URL())
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(resp.StatusCode).Should(Equal(http.StatusServiceUnavailable))
+			Expect(circuitHTTP.IsRejection(resp)).Should(BeTrue())
+		})
+		It("recognizes synthesized responses from a client with a timeout", func() {
+			client := &http.Client{
+				Timeout: 5 * time.Second,
+				Transport: circuitHTTP.NewTransport(breaker, nil, circuitHTTP.Opts{
+					RespondWhenOpen: true,
+				}),
+			}
+			_, _ = client.Get(server.URL())
+			resp, err := client.Get(server.URL())
+			Expect(err).ShouldNot(HaveOccurred())
+			defer func() {
+				_ = resp.Body.Close()
+			}()
+			Expect(resp.StatusCode).Should(Equal(http.StatusServiceUnavailable))
+			Expect(circuitHTTP.IsRejection(resp)).Should(BeTrue())
+			Expect(circuitHTTP.RejectionCause(resp)).Should(HaveOccurred())
+		})
+		It("does not mistake a forwarded rejection for its own", func() {
+			forwarding := ghttp.NewServer()
+			defer forwarding.Close()
+			forwarding.AppendHandlers(ghttp.RespondWith(http.StatusServiceUnavailable, nil, http.Header{
+				"X-Circuit-Rejected": []string{"another process"},
+			}))
+			client := &http.Client{
+				Transport: circuitHTTP.NewTransport(breaker, nil, circuitHTTP.Opts{
+					RespondWhenOpen: true,
+				}),
+			}
+			resp, err := client.Get(forwarding.URL())
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(resp.StatusCode).Should(Equal(http.StatusServiceUnavailable))
+			Expect(circuitHTTP.IsRejection(resp)).Should(BeFalse())
+		})
+	})
+})
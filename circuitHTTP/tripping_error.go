@@ -1,9 +1,13 @@
 package circuitHTTP
 
 import (
+	"context"
 	"errors"
 	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"net"
 	"net/http"
+	"syscall"
+	"time"
 )
 
 // ConvertToTrippingErrIfShould converts the http response and error, if any, into a tripping error
@@ -24,21 +28,83 @@ type Breaker interface {
 	Use(callback func() error) error
 }
 
+// Classifier is optionally implemented by a Breaker. When implemented, the breaker decides which errors count as
+// failures when retrying, hedging and failing over, otherwise only tripping errors do.
+// twoStateCircuit.Breaker and threeStateCircuit.Breaker both implement this.
+type Classifier interface {
+	Classify(err error) *tripping.Error
+}
+
+// countsAgainst is true if the breaker records err as a failure
+func countsAgainst(breaker Breaker, err error) bool {
+	if classifier, ok := breaker.(Classifier); ok {
+		return classifier.Classify(err) != nil
+	}
+	return tripping.IsTripping(err)
+}
+
+// Categories of the tripping errors created by the default TripDecider. Use them as keys of the breaker's
+// CategoryRecorders to give each its own threshold. Other errors are uncategorized
+const (
+	// CategoryTimeout is a request that timed out, or a 408 Request Timeout response
+	CategoryTimeout tripping.Category = "Timeout"
+
+	// CategoryConnectionRefused is a request the backend refused to connect to, usually because it is down
+	CategoryConnectionRefused tripping.Category = "ConnectionRefused"
+
+	// CategoryServerError is a 500, 502 or 503 response
+	CategoryServerError tripping.Category = "ServerError"
+
+	// CategoryRateLimited is a 429 Too Many Requests response
+	CategoryRateLimited tripping.Category = "RateLimited"
+)
+
 func defaultConvertToTrippingErrIfShould(resp *http.Response, err error) error {
 	// all errors trip the breaker
 	if err != nil {
-		return tripping.New(err)
+		return tripping.NewWithCategory(err, categorizeError(err))
 	}
 	// Some status codes also trip the breaker, even if there was no error
 	switch resp.StatusCode {
 	// TODO: custom errors for each condition
 	case http.StatusBadGateway, // usually coincides with the backend being down
 		http.StatusInternalServerError, // some services will throw this when overwhelmed, too
-		http.StatusRequestTimeout,      // servers usually are programmed to return this when their own req timeout expires
-		http.StatusServiceUnavailable,  // usually coincides with the backend being down
-		http.StatusTooManyRequests:     // service has a rate limiter telling us to slow down
-		return tripping.New(errors.New("upstream service is down or is rateLimit-limiting"))
+		http.StatusServiceUnavailable:  // usually coincides with the backend being down
+		return tripping.NewWithCategory(errUpstreamDown, CategoryServerError)
+	case http.StatusRequestTimeout: // servers usually are programmed to return this when their own req timeout expires
+		return tripping.NewWithCategory(errUpstreamDown, CategoryTimeout)
+	case http.StatusTooManyRequests: // service has a rate limiter telling us to slow down
+		return tripping.NewWithCategory(errUpstreamDown, CategoryRateLimited)
 	default:
 		return err
 	}
 }
+
+// defaultLatencyCost charges 1 per started second, so slow failures cost more than fast ones
+var defaultLatencyCost = tripping.LatencyCost(time.Second, 30)
+
+// DefaultCost weighs failures categorized by the default TripDecider. It's used when neither Opts.CostFunc nor
+// Opts.TripDecider is set.
+// Failures cost 1 for every started second they took, up to 30, so a 30-second timeout counts 30 times as much as a
+// fast 500. Rate limited responses always cost 1: the backend is healthy enough to answer, it wants us to slow down
+func DefaultCost(err error, latency time.Duration, category tripping.Category) uint64 {
+	if category == CategoryRateLimited {
+		return 1
+	}
+	return defaultLatencyCost(err, latency, category)
+}
+
+var errUpstreamDown = errors.New("upstream service is down or is rateLimit-limiting")
+
+// categorizeError returns the Category of an error returned while sending a request, empty if it has none
+func categorizeError(err error) tripping.Category {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return CategoryTimeout
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return CategoryConnectionRefused
+	default:
+		return ""
+	}
+}
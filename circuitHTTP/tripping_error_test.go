@@ -1,11 +1,17 @@
 package circuitHTTP
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	. "github.com/onsi/gomega"
 	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"net"
 	"net/http"
+	"os"
+	"syscall"
 	"testing"
+	"time"
 )
 
 func Test_defaultConvertToTrippingErrIfShould(t *testing.T) {
@@ -39,6 +45,124 @@ func Test_defaultConvertToTrippingErrIfShould(t *testing.T) {
 	}
 }
 
+func Test_defaultConvertToTrippingErrIfShould_Category(t *testing.T) {
+	cases := map[string]struct {
+		inputErr         error
+		inputStatus      int
+		expectedCategory tripping.Category
+	}{
+		"other errors are uncategorized": {
+			inputErr: errors.New("some error"),
+		},
+		"deadline exceeded": {
+			inputErr:         fmt.Errorf("sending: %w", context.DeadlineExceeded),
+			expectedCategory: CategoryTimeout,
+		},
+		"network timeout": {
+			inputErr:         &net.DNSError{IsTimeout: true},
+			expectedCategory: CategoryTimeout,
+		},
+		"connection refused": {
+			inputErr:         &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)},
+			expectedCategory: CategoryConnectionRefused,
+		},
+		"server error": {
+			inputStatus:      http.StatusBadGateway,
+			expectedCategory: CategoryServerError,
+		},
+		"request timeout": {
+			inputStatus:      http.StatusRequestTimeout,
+			expectedCategory: CategoryTimeout,
+		},
+		"rate limited": {
+			inputStatus:      http.StatusTooManyRequests,
+			expectedCategory: CategoryRateLimited,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			actual, ok := tripping.As(defaultConvertToTrippingErrIfShould(&http.Response{StatusCode: dt.inputStatus}, dt.inputErr))
+			g.Expect(ok).Should(BeTrue())
+			g.Expect(actual.Category).Should(Equal(dt.expectedCategory))
+		})
+	}
+}
+
+func TestDefaultCost(t *testing.T) {
+	cases := map[string]struct {
+		latency  time.Duration
+		category tripping.Category
+		expected uint64
+	}{
+		"fast failure": {
+			latency:  50 * time.Millisecond,
+			category: CategoryServerError,
+			expected: 1,
+		},
+		"slow timeout": {
+			latency:  30 * time.Second,
+			category: CategoryTimeout,
+			expected: 30,
+		},
+		"capped": {
+			latency:  time.Minute,
+			category: CategoryTimeout,
+			expected: 30,
+		},
+		"slow rate limit": {
+			latency:  10 * time.Second,
+			category: CategoryRateLimited,
+			expected: 1,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(DefaultCost(errUpstreamDown, dt.latency, dt.category)).Should(Equal(dt.expected))
+		})
+	}
+}
+
+func TestOpts_weigh(t *testing.T) {
+	slowFailure := tripping.NewWithCategory(errUpstreamDown, CategoryTimeout)
+	cases := map[string]struct {
+		opts     Opts
+		err      error
+		expected error
+	}{
+		"default TripDecider, then DefaultCost": {
+			err:      slowFailure,
+			expected: &tripping.Error{Err: errUpstreamDown, Cost: 10, Category: CategoryTimeout},
+		},
+		"custom TripDecider, then its cost": {
+			opts: Opts{
+				TripDecider: defaultConvertToTrippingErrIfShould,
+			},
+			err:      slowFailure,
+			expected: slowFailure,
+		},
+		"CostFunc": {
+			opts: Opts{
+				TripDecider: defaultConvertToTrippingErrIfShould,
+				CostFunc:    tripping.LatencyCost(time.Second, 5),
+			},
+			err:      slowFailure,
+			expected: &tripping.Error{Err: errUpstreamDown, Cost: 5, Category: CategoryTimeout},
+		},
+		"not a tripping error": {
+			err:      errUpstreamDown,
+			expected: errUpstreamDown,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(dt.opts.weigh(dt.err, 10*time.Second)).Should(Equal(dt.expected))
+		})
+	}
+}
+
 func TestConvertToTrippingErrIfShould_ConvertToTrippingErrIfShould(t *testing.T) {
 	defaultError := errors.New("default")
 	overriddenError := errors.New("overridden")
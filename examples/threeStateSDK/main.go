@@ -18,7 +18,7 @@ func main() {
 			// over the course of 60 seconds. When the breaker first enters Half-Open,
 			// the chance of being sampled is 0, slowly increasing to 50% once 60 seconds have passed
 			// Feel free to swap this out with whatever you need.
-			HalfOpenSampler: halfOpenSampler.NewLinearScalingSamplerWithStandardRandom(
+			Sampler: halfOpenSampler.NewLinearScalingSamplerWithStandardRandom(
 				60*time.Second,
 				.5,
 			),
@@ -0,0 +1,25 @@
+package halfOpenSampler
+
+import (
+	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit"
+	"sync/atomic"
+)
+
+// NewEveryNthSampler will allow the circuit breaker to test exactly 1 out of every n requests while in the Half Open
+// State, starting with the first. Unlike the random samplers, this is completely predictable: with n set to 10, the
+// 1st, 11th, 21st, ... requests are attempted. n of 0 or 1 samples every request.
+// Every request counts toward n, whatever its priority.
+//
+// Example:
+// sampler := NewEveryNthSampler(10)
+// sampler.Sample(sampleContext) -> true/false
+func NewEveryNthSampler(n uint64) threeStateCircuit.SamplerFunc {
+	if n == 0 {
+		n = 1
+	}
+	var count uint64
+	return func(_ threeStateCircuit.SampleContext) (shouldSample bool) {
+		// AddUint64 returns the new count, so the first request is count 1
+		return (atomic.AddUint64(&count, 1)-1)%n == 0
+	}
+}
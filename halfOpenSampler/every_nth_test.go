@@ -0,0 +1,37 @@
+package halfOpenSampler
+
+import (
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestNewEveryNthSampler(t *testing.T) {
+	cases := map[string]struct {
+		n        uint64
+		expected []bool
+	}{
+		"every third": {
+			n:        3,
+			expected: []bool{true, false, false, true, false, false, true},
+		},
+		"every one": {
+			n:        1,
+			expected: []bool{true, true, true},
+		},
+		"zero samples everything": {
+			n:        0,
+			expected: []bool{true, true, true},
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			subject := NewEveryNthSampler(dt.n)
+			actual := make([]bool, len(dt.expected))
+			for i := range actual {
+				actual[i] = subject.ShouldSample(0)
+			}
+			g.Expect(actual).Should(Equal(dt.expected))
+		})
+	}
+}
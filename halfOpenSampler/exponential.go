@@ -0,0 +1,52 @@
+package halfOpenSampler
+
+import (
+	"github.com/wojnosystems/go-circuit-breaker/priority"
+	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// NewExponentialScalingSampler will allow the circuit breaker to test a percentage of requests while in the Half Open
+// State. The chance of the request being tested starts at startingChance and grows exponentially with time, reaching
+// maximumChance once scaleOverDuration has passed. Compared to NewLinearScalingSampler, this sends very few probes
+// at first and then ramps up quickly, doubling the chance over equal slices of time.
+// For example, if you set:
+//
+//	scaleOverDuration: 30 * time.Second
+//	startingChance: 0.01
+//	maximumChance: 0.64
+//
+// The chance doubles every 5 seconds: 1%, 2%, 4%, ... until every request has a 64% chance of being attempted.
+// These are the chances of Default requests, the chance is scaled by the request's priority, see priority.Chance.
+// A startingChance of 0 or less cannot grow exponentially, so every request has maximumChance from the start.
+// The randomSource does not need to be thread-safe, this method will ensure that it's not used concurrently,
+// assuming no other threads are also using this same random source.
+//
+// Example:
+// sampler := NewExponentialScalingSampler(30 * time.Second, 0.01, 0.64, rand.NewSource(time.Now().UnixNano()))
+// sampler.Sample(sampleContext) -> true/false
+func NewExponentialScalingSampler(scaleOverDuration time.Duration, startingChance float64, maximumChance float64, randomSource rand.Source) threeStateCircuit.SamplerFunc {
+	randSource := rand.New(randomSource)
+	var mu sync.Mutex
+	return func(sampleContext threeStateCircuit.SampleContext) (shouldSample bool) {
+		mu.Lock()
+		randomValue := randSource.Float64()
+		mu.Unlock()
+		chancePercent := maximumChance
+		if sampleContext.TimeInHalfOpen < scaleOverDuration && startingChance > 0 {
+			progress := float64(sampleContext.TimeInHalfOpen) / float64(scaleOverDuration)
+			chancePercent = startingChance * math.Pow(maximumChance/startingChance, progress)
+		}
+		return randomValue < priority.Chance(sampleContext.Priority, chancePercent)
+	}
+}
+
+// NewExponentialScalingSamplerWithStandardRandom works exactly like NewExponentialScalingSampler, but
+// the randomSource is math.Random seeded with the current time.
+// This is a convenience method.
+func NewExponentialScalingSamplerWithStandardRandom(scaleOverDuration time.Duration, startingChance float64, maximumChance float64) threeStateCircuit.SamplerFunc {
+	return NewExponentialScalingSampler(scaleOverDuration, startingChance, maximumChance, rand.NewSource(time.Now().UnixNano()))
+}
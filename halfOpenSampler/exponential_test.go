@@ -0,0 +1,68 @@
+package halfOpenSampler
+
+import (
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestNewExponentialScalingSampler(t *testing.T) {
+	cases := map[string]struct {
+		startingChance float64
+		maximumChance  float64
+		timeInHalfOpen time.Duration
+		expectedChance float64
+	}{
+		"when no time spent, then starting chance": {
+			startingChance: 0.01,
+			maximumChance:  0.64,
+			timeInHalfOpen: 0,
+			expectedChance: 0.01,
+		},
+		"half way, then doubled 3 times": {
+			startingChance: 0.01,
+			maximumChance:  0.64,
+			timeInHalfOpen: 15 * time.Second,
+			expectedChance: 0.08,
+		},
+		"all time spent, then maximum chance": {
+			startingChance: 0.01,
+			maximumChance:  0.64,
+			timeInHalfOpen: time.Minute,
+			expectedChance: 0.64,
+		},
+		"no starting chance, then maximum chance from the start": {
+			startingChance: 0,
+			maximumChance:  0.64,
+			timeInHalfOpen: 0,
+			expectedChance: 0.64,
+		},
+		"never sample": {
+			startingChance: 0,
+			maximumChance:  0,
+			timeInHalfOpen: time.Minute,
+			expectedChance: 0,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			subject := NewExponentialScalingSampler(30*time.Second, dt.startingChance, dt.maximumChance, rand.NewSource(42))
+			g.Expect(sampledFraction(subject, dt.timeInHalfOpen)).Should(BeNumerically("~", dt.expectedChance, 0.01))
+		})
+	}
+}
+
+// sampledFraction is the fraction of many requests that are sampled at timeInHalfOpen
+func sampledFraction(sampler threeStateCircuit.Sampler, timeInHalfOpen time.Duration) float64 {
+	const requests = 100000
+	sampled := 0
+	for i := 0; i < requests; i++ {
+		if sampler.Sample(threeStateCircuit.SampleContext{TimeInHalfOpen: timeInHalfOpen}) {
+			sampled++
+		}
+	}
+	return float64(sampled) / requests
+}
@@ -0,0 +1,37 @@
+package halfOpenSampler
+
+import (
+	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit"
+	"sync/atomic"
+	"time"
+)
+
+// NewFirstAfterIntervalSampler will allow the circuit breaker to test the first request in the Half Open State, then
+// the first request once interval has passed since the last sampled request. With interval set to 5 seconds, at most
+// 1 request is attempted every 5 seconds, no matter how much traffic there is. Like NewEveryNthSampler, this is
+// completely predictable. An interval of 0 samples every request. The request's priority is not considered.
+// A request is also sampled whenever no sampled request is in flight or has succeeded, such as the first request
+// each time the breaker enters the Half Open State.
+//
+// Example:
+// sampler := NewFirstAfterIntervalSampler(5 * time.Second)
+// sampler.Sample(sampleContext) -> true/false
+func NewFirstAfterIntervalSampler(interval time.Duration) threeStateCircuit.SamplerFunc {
+	if interval <= 0 {
+		return func(_ threeStateCircuit.SampleContext) (shouldSample bool) {
+			return true
+		}
+	}
+	// lastSampledAt is the timeInHalfOpen of the last sampled request
+	var lastSampledAt int64
+	return func(sampleContext threeStateCircuit.SampleContext) (shouldSample bool) {
+		lastSampled := atomic.LoadInt64(&lastSampledAt)
+		// nothing sampled is pending or has succeeded, so this is a new half-open round or the last probe went unheard
+		newRound := sampleContext.InFlight == 0 && sampleContext.Successes == 0
+		if !newRound && int64(sampleContext.TimeInHalfOpen) < lastSampled+int64(interval) {
+			return false
+		}
+		// only one of the concurrent requests wins
+		return atomic.CompareAndSwapInt64(&lastSampledAt, lastSampled, int64(sampleContext.TimeInHalfOpen))
+	}
+}
@@ -0,0 +1,56 @@
+package halfOpenSampler
+
+import (
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit"
+	"testing"
+	"time"
+)
+
+func TestNewFirstAfterIntervalSampler(t *testing.T) {
+	cases := map[string]struct {
+		interval       time.Duration
+		sampleContexts []threeStateCircuit.SampleContext
+		expected       []bool
+	}{
+		"samples the first after each interval": {
+			interval: 5 * time.Second,
+			sampleContexts: []threeStateCircuit.SampleContext{
+				{TimeInHalfOpen: time.Second},
+				{TimeInHalfOpen: 2 * time.Second, InFlight: 1},
+				{TimeInHalfOpen: 6 * time.Second, Successes: 1},
+				{TimeInHalfOpen: 7 * time.Second, InFlight: 1, Successes: 1},
+				{TimeInHalfOpen: 11 * time.Second, Successes: 2},
+			},
+			expected: []bool{true, false, true, false, true},
+		},
+		"samples the first of each half-open round": {
+			interval: 5 * time.Second,
+			sampleContexts: []threeStateCircuit.SampleContext{
+				{TimeInHalfOpen: 3 * time.Second},
+				{TimeInHalfOpen: 4 * time.Second, InFlight: 1},
+				// the probe failed, the breaker re-opened and is now half-open again
+				{TimeInHalfOpen: 4 * time.Second},
+				{TimeInHalfOpen: 5 * time.Second, InFlight: 1},
+				{TimeInHalfOpen: 6 * time.Second},
+			},
+			expected: []bool{true, false, true, false, true},
+		},
+		"zero samples everything": {
+			interval:       0,
+			sampleContexts: []threeStateCircuit.SampleContext{{}, {InFlight: 1}, {Successes: 1}},
+			expected:       []bool{true, true, true},
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			subject := NewFirstAfterIntervalSampler(dt.interval)
+			actual := make([]bool, len(dt.sampleContexts))
+			for i, sampleContext := range dt.sampleContexts {
+				actual[i] = subject.Sample(sampleContext)
+			}
+			g.Expect(actual).Should(Equal(dt.expected))
+		})
+	}
+}
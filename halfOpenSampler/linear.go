@@ -1,6 +1,7 @@
 package halfOpenSampler
 
 import (
+	"github.com/wojnosystems/go-circuit-breaker/priority"
 	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit"
 	"math/rand"
 	"sync"
@@ -17,6 +18,7 @@ import (
 // If the breaker has been in the half-open state for 10 seconds, there is a 25% * 33% or 8.25% chance that
 // the circuit breaker will attempt the request. Approximately every 1 in 12 usage attempts will actually send a request.
 // After 30 seconds, every request has a 25% chance or roughly 1 out of every 4 requests of being attempted.
+// These are the chances of Default requests, the chance is scaled by the request's priority, see priority.Chance.
 // The randomSource allows you to specify a custom source of randomness, in case you want to seed it or use something
 // different. Usually, the pseudo-random number generator provided by math.Rand will suffice.
 // The randomSource does not need to be thread-safe, this method will ensure that it's not used concurrently,
@@ -24,25 +26,25 @@ import (
 //
 // Example:
 // sampler := NewLinearScalingSampler(30 * time.Second, 0.25, rand.NewSource(time.Now().UnixNano()))
-// sampler.ShouldSample(timeInHalfOpen) -> true/false
-func NewLinearScalingSampler(scaleOverDuration time.Duration, maximumChance float64, randomSource rand.Source) threeStateCircuit.ShouldSample {
+// sampler.Sample(sampleContext) -> true/false
+func NewLinearScalingSampler(scaleOverDuration time.Duration, maximumChance float64, randomSource rand.Source) threeStateCircuit.SamplerFunc {
 	randSource := rand.New(randomSource)
 	var mu sync.Mutex
-	return func(timeInHalfOpen time.Duration) (shouldSample bool) {
+	return func(sampleContext threeStateCircuit.SampleContext) (shouldSample bool) {
 		mu.Lock()
 		randomValue := randSource.Float64()
 		mu.Unlock()
-		if timeInHalfOpen >= scaleOverDuration {
-			return randomValue < maximumChance
+		chancePercent := maximumChance
+		if sampleContext.TimeInHalfOpen < scaleOverDuration {
+			chancePercent = float64(sampleContext.TimeInHalfOpen) / float64(scaleOverDuration) * maximumChance
 		}
-		chancePercent := float64(timeInHalfOpen) / float64(scaleOverDuration) * maximumChance
-		return randomValue < chancePercent
+		return randomValue < priority.Chance(sampleContext.Priority, chancePercent)
 	}
 }
 
 // NewLinearScalingSamplerWithStandardRandom works exactly like NewLinearScalingSampler, but
 // the randomSource is math.Random seeded with the current time.
 // This is a convenience method.
-func NewLinearScalingSamplerWithStandardRandom(scaleOverDuration time.Duration, maximumChance float64) threeStateCircuit.ShouldSample {
+func NewLinearScalingSamplerWithStandardRandom(scaleOverDuration time.Duration, maximumChance float64) threeStateCircuit.SamplerFunc {
 	return NewLinearScalingSampler(scaleOverDuration, maximumChance, rand.NewSource(time.Now().UnixNano()))
 }
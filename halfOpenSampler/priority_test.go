@@ -0,0 +1,60 @@
+package halfOpenSampler
+
+import (
+	"context"
+	"errors"
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/priority"
+	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestSamplers_WithPriority(t *testing.T) {
+	samplers := map[string]func() threeStateCircuit.Sampler{
+		"every nth": func() threeStateCircuit.Sampler {
+			return NewEveryNthSampler(3)
+		},
+		"first after interval": func() threeStateCircuit.Sampler {
+			return NewFirstAfterIntervalSampler(time.Millisecond)
+		},
+		"probes per second": func() threeStateCircuit.Sampler {
+			return NewProbesPerSecondSampler(1000)
+		},
+		"exponential": func() threeStateCircuit.Sampler {
+			return NewExponentialScalingSampler(10*time.Millisecond, 0.1, 0.5, rand.NewSource(42))
+		},
+		"step": func() threeStateCircuit.Sampler {
+			return NewStepSampler([]Step{{Chance: 0.1}, {After: 10 * time.Millisecond, Chance: 0.5}}, rand.NewSource(42))
+		},
+	}
+	priorities := []priority.Priority{priority.Sheddable, priority.Default, priority.Critical}
+	for samplerName, newSampler := range samplers {
+		for _, p := range priorities {
+			t.Run(samplerName+" "+p.String(), func(t *testing.T) {
+				g := NewWithT(t)
+				breaker := threeStateCircuit.New(threeStateCircuit.Opts{
+					Recorder:                           tripping.ConsecutiveFailures(1),
+					OpenDuration:                       time.Millisecond,
+					Sampler:                            newSampler(),
+					NumberOfSuccessesInHalfOpenToClose: 3,
+				})
+				_ = breaker.Use(func() error {
+					return tripping.New(errors.New("failed"))
+				})
+				g.Expect(breaker.CircuitState()).Should(Equal("Open"))
+				time.Sleep(2 * time.Millisecond)
+
+				ctx := priority.WithPriority(context.Background(), p)
+				g.Eventually(func() string {
+					_ = breaker.UseContext(ctx, func() error {
+						return nil
+					})
+					return breaker.CircuitState()
+				}, time.Second, 100*time.Microsecond).Should(Equal("Closed"))
+			})
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package halfOpenSampler
+
+import (
+	"github.com/wojnosystems/go-circuit-breaker/priority"
+	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Step is the chance of sampling a request once the breaker has been in the half-open state for After
+type Step struct {
+	After  time.Duration
+	Chance float64
+}
+
+// NewStepSampler will allow the circuit breaker to test a percentage of requests while in the Half Open State,
+// following a schedule of steps. Each request uses the chance of the latest step that has been reached, and no
+// requests are sampled before the first step.
+// For example, if you set:
+//
+//	steps: []Step{{After: 0, Chance: 0.01}, {After: 10 * time.Second, Chance: 0.1}, {After: time.Minute, Chance: 0.5}}
+//
+// 1% of requests are sampled for the first 10 seconds, then 10% until a minute has passed, then 50%.
+// These are the chances of Default requests, the chance is scaled by the request's priority, see priority.Chance.
+// The order of steps does not matter.
+// The randomSource does not need to be thread-safe, this method will ensure that it's not used concurrently,
+// assuming no other threads are also using this same random source.
+//
+// Example:
+// sampler := NewStepSampler(steps, rand.NewSource(time.Now().UnixNano()))
+// sampler.Sample(sampleContext) -> true/false
+func NewStepSampler(steps []Step, randomSource rand.Source) threeStateCircuit.SamplerFunc {
+	sortedSteps := make([]Step, len(steps))
+	copy(sortedSteps, steps)
+	sort.Slice(sortedSteps, func(i, j int) bool {
+		return sortedSteps[i].After < sortedSteps[j].After
+	})
+	randSource := rand.New(randomSource)
+	var mu sync.Mutex
+	return func(sampleContext threeStateCircuit.SampleContext) (shouldSample bool) {
+		// index of the first step not yet reached
+		next := sort.Search(len(sortedSteps), func(i int) bool {
+			return sortedSteps[i].After > sampleContext.TimeInHalfOpen
+		})
+		if next == 0 {
+			return false
+		}
+		mu.Lock()
+		randomValue := randSource.Float64()
+		mu.Unlock()
+		return randomValue < priority.Chance(sampleContext.Priority, sortedSteps[next-1].Chance)
+	}
+}
+
+// NewStepSamplerWithStandardRandom works exactly like NewStepSampler, but the randomSource is math.Random seeded with
+// the current time.
+// This is a convenience method.
+func NewStepSamplerWithStandardRandom(steps []Step) threeStateCircuit.SamplerFunc {
+	return NewStepSampler(steps, rand.NewSource(time.Now().UnixNano()))
+}
@@ -0,0 +1,44 @@
+package halfOpenSampler
+
+import (
+	. "github.com/onsi/gomega"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestNewStepSampler(t *testing.T) {
+	steps := []Step{
+		{After: time.Minute, Chance: 1},
+		{After: time.Second, Chance: 0},
+		{After: 10 * time.Second, Chance: 0.5},
+	}
+	cases := map[string]struct {
+		timeInHalfOpen time.Duration
+		expectedChance float64
+	}{
+		"before the first step, then never": {
+			timeInHalfOpen: 0,
+			expectedChance: 0,
+		},
+		"at a step, then its chance": {
+			timeInHalfOpen: 10 * time.Second,
+			expectedChance: 0.5,
+		},
+		"between steps, then the earlier chance": {
+			timeInHalfOpen: 30 * time.Second,
+			expectedChance: 0.5,
+		},
+		"after the last step, then its chance": {
+			timeInHalfOpen: time.Hour,
+			expectedChance: 1,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			subject := NewStepSampler(steps, rand.NewSource(42))
+			g.Expect(sampledFraction(subject, dt.timeInHalfOpen)).Should(BeNumerically("~", dt.expectedChance, 0.01))
+		})
+	}
+}
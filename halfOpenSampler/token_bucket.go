@@ -0,0 +1,43 @@
+package halfOpenSampler
+
+import (
+	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit"
+	"github.com/wojnosystems/go-rate-limit/rateLimit"
+	"math"
+	"sync"
+)
+
+// NewTokenBucketSampler will allow the circuit breaker to test requests while in the Half Open State as long as the
+// limiter allows them, each request costing 1 token. Use this to send a fixed number of probes each second no matter
+// how much traffic there is. Requests cost the same whatever their priority.
+// The limiter does not need to be thread-safe, each sampler ensures it's not used concurrently, assuming no other
+// threads are also using this same limiter.
+//
+// Example:
+//
+//	sampler := NewTokenBucketSampler(rateLimit.NewTokenBucket(rateLimit.TokenBucketOpts{
+//	  Capacity:             1,
+//	  TokensAddedPerSecond: 2,
+//	  InitialTokens:        1,
+//	}))
+//
+// sampler.Sample(sampleContext) -> true/false
+func NewTokenBucketSampler(limiter rateLimit.Limiter) threeStateCircuit.SamplerFunc {
+	var mu sync.Mutex
+	return func(_ threeStateCircuit.SampleContext) (shouldSample bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		return limiter.Allowed(1)
+	}
+}
+
+// NewProbesPerSecondSampler works exactly like NewTokenBucketSampler, but the limiter is a token bucket allowing up to
+// probesPerSecond requests each second, without bursting, that allows the first request right away.
+// This is a convenience method.
+func NewProbesPerSecondSampler(probesPerSecond float64) threeStateCircuit.SamplerFunc {
+	return NewTokenBucketSampler(rateLimit.NewTokenBucket(rateLimit.TokenBucketOpts{
+		Capacity:             uint64(math.Max(1, math.Ceil(probesPerSecond))),
+		TokensAddedPerSecond: probesPerSecond,
+		InitialTokens:        1,
+	}))
+}
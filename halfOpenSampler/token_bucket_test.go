@@ -0,0 +1,53 @@
+package halfOpenSampler
+
+import (
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-rate-limit/rateLimit"
+	"testing"
+)
+
+type limiterAllowsTimes struct {
+	remaining int
+}
+
+func (l *limiterAllowsTimes) Allowed(_ uint64) bool {
+	if l.remaining == 0 {
+		return false
+	}
+	l.remaining--
+	return true
+}
+
+func TestNewTokenBucketSampler(t *testing.T) {
+	cases := map[string]struct {
+		limiter  rateLimit.Limiter
+		expected []bool
+	}{
+		"samples while allowed": {
+			limiter:  &limiterAllowsTimes{remaining: 2},
+			expected: []bool{true, true, false, false},
+		},
+		"never samples when empty": {
+			limiter:  &limiterAllowsTimes{},
+			expected: []bool{false, false},
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			subject := NewTokenBucketSampler(dt.limiter)
+			actual := make([]bool, len(dt.expected))
+			for i := range actual {
+				actual[i] = subject.ShouldSample(0)
+			}
+			g.Expect(actual).Should(Equal(dt.expected))
+		})
+	}
+}
+
+func TestNewProbesPerSecondSampler(t *testing.T) {
+	g := NewWithT(t)
+	subject := NewProbesPerSecondSampler(1)
+	g.Expect(subject.ShouldSample(0)).Should(BeTrue())
+	g.Expect(subject.ShouldSample(0)).Should(BeFalse())
+}
@@ -0,0 +1,47 @@
+package hierarchy
+
+import "sync"
+
+// Keyed lazily creates a child of a Node for each key, such as a breaker per endpoint under the service-wide breaker.
+// Keep the number of distinct keys small, a breaker is created for each one. Use NewKeyed to create one
+type Keyed struct {
+	parent     *Node
+	newBreaker func(key string) Breaker
+	opts       Opts
+
+	mu       sync.RWMutex
+	children map[string]*Node
+}
+
+// NewKeyed creates children of parent, named by their key. newBreaker is called once, the first time each key is seen
+func NewKeyed(parent *Node, newBreaker func(key string) Breaker, opts Opts) *Keyed {
+	return &Keyed{
+		parent:     parent,
+		newBreaker: newBreaker,
+		opts:       opts,
+		children:   make(map[string]*Node),
+	}
+}
+
+// Get returns the child for the key, creating it if it does not exist
+func (k *Keyed) Get(key string) *Node {
+	k.mu.RLock()
+	child, ok := k.children[key]
+	k.mu.RUnlock()
+	if ok {
+		return child
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if child, ok = k.children[key]; !ok {
+		child = k.parent.Child(key, k.newBreaker(key), k.opts)
+		k.children[key] = child
+	}
+	return child
+}
+
+// Use calls callback through the child for the key, see Node.Use
+func (k *Keyed) Use(key string, callback func() error) error {
+	return k.Get(key).Use(callback)
+}
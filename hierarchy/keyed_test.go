@@ -0,0 +1,27 @@
+package hierarchy
+
+import (
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestKeyed(t *testing.T) {
+	g := NewWithT(t)
+	root := New("service", newBreaker(10))
+	created := 0
+	subject := NewKeyed(root, func(key string) Breaker {
+		created++
+		return newBreaker(1)
+	}, Opts{})
+
+	g.Expect(subject.Get("GET /things")).Should(BeIdenticalTo(subject.Get("GET /things")))
+	g.Expect(created).Should(Equal(1))
+
+	_ = subject.Use("GET /things", func() error {
+		return trippingError
+	})
+	g.Expect(isCalled(subject.Get("GET /things"))).Should(BeFalse())
+	g.Expect(isCalled(subject.Get("GET /others"))).Should(BeTrue())
+	g.Expect(subject.Get("GET /others").Parent()).Should(BeIdenticalTo(root))
+	g.Expect(created).Should(Equal(2))
+}
@@ -0,0 +1,167 @@
+package hierarchy
+
+import (
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"math"
+	"sync"
+)
+
+// Breaker protects a level of the hierarchy. twoStateCircuit.Breaker and threeStateCircuit.Breaker both implement this.
+// Breakers must not record outcomes wrapped by tripping.Unrecorded
+type Breaker interface {
+	Use(callback func() error) error
+}
+
+type Opts struct {
+	// Weight is how much each failure counts against the parent, as a multiple of its cost, such as 0.25 for every
+	// 4th failure to count. Fractions are carried over to the next failure. Leave 0 to count every failure in full
+	Weight float64
+}
+
+// weight is Weight, defaulting to 1
+func (o Opts) weight() float64 {
+	if o.Weight <= 0 {
+		return 1
+	}
+	return o.Weight
+}
+
+// Node is a level of a hierarchy of breakers, such as a breaker per endpoint under a breaker for the whole service.
+// A call through a Node must be admitted by every breaker from the root down to the Node, so when the service-wide
+// breaker opens, every endpoint is rejected, while an endpoint's breaker only rejects calls to that endpoint.
+//
+// Failures propagate upward, weighed by each level's Opts.Weight, so many endpoints failing together trip the
+// service-wide breaker. A call rejected by a lower level, or one whose failure weighs nothing, is neither a success
+// nor a failure for the levels above it, see tripping.Unrecorded. Failures are propagated if the callback returns a
+// tripping error, other errors are passed upward unchanged, so give each level the same Classifier.
+//
+// Use New to create the root and Child to create the levels below it. Nodes are thread-safe
+type Node struct {
+	name    string
+	breaker Breaker
+	parent  *Node
+	opts    Opts
+
+	mu sync.Mutex
+
+	// owed is the weighed cost of failures not yet propagated to the parent, always less than 1
+	owed float64
+
+	admitted       uint64
+	rejectedBy     map[string]uint64
+	lastRejectedBy string
+}
+
+// New creates the root of a hierarchy, such as a breaker for the whole service. Its Opts are ignored as it has no
+// parent
+func New(name string, breaker Breaker) *Node {
+	return newNode(name, breaker, nil, Opts{})
+}
+
+// Child creates a level below n, such as a breaker for one of the service's endpoints
+func (n *Node) Child(name string, breaker Breaker, opts Opts) *Node {
+	return newNode(name, breaker, n, opts)
+}
+
+func newNode(name string, breaker Breaker, parent *Node, opts Opts) *Node {
+	return &Node{
+		name:       name,
+		breaker:    breaker,
+		parent:     parent,
+		opts:       opts,
+		rejectedBy: make(map[string]uint64),
+	}
+}
+
+// Name of the level
+func (n *Node) Name() string {
+	return n.name
+}
+
+// Parent is the level above n, nil for the root
+func (n *Node) Parent() *Node {
+	return n.parent
+}
+
+// Use calls callback if every breaker from the root down to n admits it, and records the outcome with each of them.
+// Returns the rejecting breaker's error if it was rejected, otherwise the callback's error, like a breaker's Use
+func (n *Node) Use(callback func() error) error {
+	var (
+		err       error
+		rejection *rejection
+	)
+	call := func() error {
+		err = callback()
+		return err
+	}
+	// the root's breaker is the outermost, so it decides first
+	for level := n; level != nil; level = level.parent {
+		call = level.wrap(call, &rejection)
+	}
+	_ = call()
+
+	if rejection != nil {
+		n.recordRejection(rejection.by)
+		return rejection.err
+	}
+	n.recordAdmission()
+	return tripping.Strip(err)
+}
+
+// rejection is the level that rejected a call and the error its breaker rejected it with
+type rejection struct {
+	by  *Node
+	err error
+}
+
+// wrap calls inner through n's breaker. The returned func returns the outcome as the parent should record it
+func (n *Node) wrap(inner func() error, rejected **rejection) func() error {
+	return func() error {
+		admitted := false
+		var outcome error
+		err := n.breaker.Use(func() error {
+			admitted = true
+			outcome = inner()
+			return outcome
+		})
+		if !admitted {
+			*rejected = &rejection{by: n, err: err}
+			// the call never reached the backend, so says nothing about its health
+			return tripping.Unrecorded(nil)
+		}
+		return n.weigh(outcome)
+	}
+}
+
+// weigh converts a failure at this level into the failure the parent should record, unrecorded if it weighs nothing
+func (n *Node) weigh(outcome error) error {
+	trippingErr, ok := tripping.As(outcome)
+	if !ok || n.opts.weight() == 1 {
+		return outcome
+	}
+	n.mu.Lock()
+	n.owed += n.opts.weight() * float64(trippingErr.Cost)
+	cost := math.Floor(n.owed)
+	n.owed -= cost
+	n.mu.Unlock()
+	if cost == 0 {
+		// a failure is never a success, the fraction is carried over to the next failure instead
+		return tripping.Unrecorded(nil)
+	}
+	weighed := *trippingErr
+	weighed.Cost = uint64(cost)
+	return &weighed
+}
+
+func (n *Node) recordAdmission() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.admitted++
+}
+
+func (n *Node) recordRejection(by *Node) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.rejectedBy[by.name]++
+	n.lastRejectedBy = by.name
+}
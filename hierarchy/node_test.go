@@ -0,0 +1,205 @@
+package hierarchy
+
+import (
+	"errors"
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"github.com/wojnosystems/go-circuit-breaker/twoStateCircuit"
+	"testing"
+	"time"
+)
+
+var (
+	errFailed     = errors.New("failed")
+	trippingError = tripping.New(errFailed)
+)
+
+func newBreaker(consecutiveFailures uint64) *twoStateCircuit.Breaker {
+	return twoStateCircuit.New(twoStateCircuit.Opts{
+		Recorder:     tripping.ConsecutiveFailures(consecutiveFailures),
+		OpenDuration: time.Hour,
+	})
+}
+
+// failTimes makes calls through the node that fail
+func failTimes(node *Node, times int) {
+	for i := 0; i < times; i++ {
+		_ = node.Use(func() error {
+			return trippingError
+		})
+	}
+}
+
+// isCalled is true if the node admits a call
+func isCalled(node *Node) bool {
+	called := false
+	_ = node.Use(func() error {
+		called = true
+		return nil
+	})
+	return called
+}
+
+func TestNode_Use(t *testing.T) {
+	cases := map[string]struct {
+		weight            float64
+		failures          int
+		expectedCalled    bool
+		expectedRoot      string
+		expectedRejecter  string
+		expectedSiblingOK bool
+	}{
+		"admits while healthy": {
+			failures:          1,
+			expectedCalled:    true,
+			expectedRoot:      "Closed",
+			expectedSiblingOK: true,
+		},
+		"one bad child opens only itself": {
+			weight:            0.25,
+			failures:          2,
+			expectedRoot:      "Closed",
+			expectedRejecter:  "child",
+			expectedSiblingOK: true,
+		},
+		"failures propagate to the root": {
+			failures:         2,
+			expectedRoot:     "Open",
+			expectedRejecter: "root",
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			root := New("root", newBreaker(2))
+			child := root.Child("child", newBreaker(2), Opts{Weight: dt.weight})
+			sibling := root.Child("sibling", newBreaker(2), Opts{})
+
+			failTimes(child, dt.failures)
+
+			g.Expect(isCalled(child)).Should(Equal(dt.expectedCalled))
+			g.Expect(root.Snapshot().Levels[0].State).Should(Equal(dt.expectedRoot))
+			g.Expect(child.Snapshot().LastRejectedBy).Should(Equal(dt.expectedRejecter))
+			g.Expect(isCalled(sibling)).Should(Equal(dt.expectedSiblingOK))
+		})
+	}
+}
+
+func TestNode_Use_ReturnsErrors(t *testing.T) {
+	g := NewWithT(t)
+	root := New("root", newBreaker(1))
+	child := root.Child("child", newBreaker(5), Opts{})
+
+	err := child.Use(func() error {
+		return trippingError
+	})
+	g.Expect(err).Should(Equal(errFailed))
+
+	err = child.Use(func() error {
+		return nil
+	})
+	g.Expect(err).Should(Equal(errFailed), "rejected with the root's last error")
+}
+
+func TestNode_Use_HalfOpenRootAboveOpenChild(t *testing.T) {
+	g := NewWithT(t)
+	rootBreaker := threeStateCircuit.New(threeStateCircuit.Opts{
+		Recorder:                           tripping.ConsecutiveFailures(1),
+		OpenDuration:                       time.Millisecond,
+		NumberOfSuccessesInHalfOpenToClose: 1,
+	})
+	root := New("root", rootBreaker)
+	child := root.Child("child", newBreaker(1), Opts{})
+	failTimes(child, 1)
+	time.Sleep(2 * time.Millisecond)
+
+	g.Expect(isCalled(child)).Should(BeFalse())
+	g.Expect(child.Snapshot().LastRejectedBy).Should(Equal("child"))
+	g.Expect(rootBreaker.CircuitState()).Should(Equal("HalfOpen"))
+}
+
+func TestNode_Use_WeighedFailureIsNotASuccess(t *testing.T) {
+	g := NewWithT(t)
+	root := New("root", newBreaker(2))
+	child := root.Child("child", newBreaker(10), Opts{Weight: 0.5})
+	failTimes(root, 1)
+	failTimes(child, 1)
+	failTimes(child, 1)
+	g.Expect(root.Snapshot().Levels[0].State).Should(Equal("Open"))
+}
+
+func TestNode_Weight(t *testing.T) {
+	cases := map[string]struct {
+		weight   float64
+		cost     uint64
+		failures int
+		expected []uint64
+	}{
+		"full weight": {
+			cost:     2,
+			failures: 2,
+			expected: []uint64{2, 2},
+		},
+		"carries fractions over": {
+			weight:   0.4,
+			cost:     1,
+			failures: 5,
+			expected: []uint64{0, 0, 1, 0, 1},
+		},
+		"scales the cost": {
+			weight:   0.5,
+			cost:     4,
+			failures: 2,
+			expected: []uint64{2, 2},
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			subject := newNode("child", newBreaker(1), nil, Opts{Weight: dt.weight})
+			var actual []uint64
+			for i := 0; i < dt.failures; i++ {
+				weighed, _ := tripping.As(subject.weigh(tripping.NewWithCost(errFailed, dt.cost)))
+				if weighed == nil {
+					actual = append(actual, 0)
+				} else {
+					actual = append(actual, weighed.Cost)
+				}
+			}
+			g.Expect(actual).Should(Equal(dt.expected))
+		})
+	}
+}
+
+func TestNode_Snapshot(t *testing.T) {
+	g := NewWithT(t)
+	root := New("root", newBreaker(10))
+	child := root.Child("child", newBreaker(1), Opts{})
+	g.Expect(isCalled(child)).Should(BeTrue())
+	failTimes(child, 3)
+
+	g.Expect(child.Snapshot()).Should(Equal(Snapshot{
+		Name: "child",
+		Levels: []Level{
+			{Name: "root", State: "Closed"},
+			{Name: "child", State: "Open"},
+		},
+		Admitted:       2,
+		RejectedBy:     map[string]uint64{"child": 2},
+		LastRejectedBy: "child",
+	}))
+}
+
+func TestNode_Snapshot_UnknownState(t *testing.T) {
+	g := NewWithT(t)
+	root := New("root", passThrough{})
+	g.Expect(root.Snapshot().Levels).Should(Equal([]Level{{Name: "root", State: "Unknown"}}))
+}
+
+// passThrough is a Breaker that always calls the callback and can't describe its state
+type passThrough struct{}
+
+func (passThrough) Use(callback func() error) error {
+	return callback()
+}
@@ -0,0 +1,65 @@
+package hierarchy
+
+// StateDescriber is optionally implemented by a Breaker, so snapshots can report the state each level is in.
+// twoStateCircuit.Breaker and threeStateCircuit.Breaker both implement this
+type StateDescriber interface {
+	CircuitState() string
+}
+
+// unknownState is reported for levels whose breaker cannot describe its own state
+const unknownState = "Unknown"
+
+// Level is the state of one of the breakers a call passes through
+type Level struct {
+	Name  string
+	State string
+}
+
+// Snapshot is a copy of a Node's state at a moment in time, use it for metrics and debugging
+type Snapshot struct {
+	Name string
+
+	// Levels the calls pass through, from the root down to the Node
+	Levels []Level
+
+	// Admitted counts the calls made through the Node that every level admitted
+	Admitted uint64
+
+	// RejectedBy counts the calls made through the Node that were rejected, by the name of the level that rejected them
+	RejectedBy map[string]uint64
+
+	// LastRejectedBy is the name of the level that rejected the most recent rejected call, empty if none were
+	LastRejectedBy string
+}
+
+// Snapshot copies the Node's current state
+func (n *Node) Snapshot() Snapshot {
+	n.mu.Lock()
+	snapshot := Snapshot{
+		Name:           n.name,
+		Admitted:       n.admitted,
+		RejectedBy:     make(map[string]uint64, len(n.rejectedBy)),
+		LastRejectedBy: n.lastRejectedBy,
+	}
+	for name, rejected := range n.rejectedBy {
+		snapshot.RejectedBy[name] = rejected
+	}
+	n.mu.Unlock()
+
+	for level := n; level != nil; level = level.parent {
+		snapshot.Levels = append([]Level{level.describe()}, snapshot.Levels...)
+	}
+	return snapshot
+}
+
+// describe the level's breaker
+func (n *Node) describe() Level {
+	level := Level{
+		Name:  n.name,
+		State: unknownState,
+	}
+	if describer, ok := n.breaker.(StateDescriber); ok {
+		level.State = describer.CircuitState()
+	}
+	return level
+}
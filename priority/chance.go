@@ -0,0 +1,16 @@
+package priority
+
+// Chance scales chance, the chance of attempting a Default request, for a request with priority p. Critical requests
+// get a second chance and Sheddable requests must win twice, so with a chance of 50%, 75% of Critical, 50% of Default
+// and 25% of Sheddable requests are admitted. Compare the result with a single random draw. When every request is
+// admitted, or none are, priority makes no difference.
+func Chance(p Priority, chance float64) float64 {
+	switch p {
+	case Critical:
+		return 1 - (1-chance)*(1-chance)
+	case Sheddable:
+		return chance * chance
+	default:
+		return chance
+	}
+}
@@ -0,0 +1,47 @@
+package priority_test
+
+import (
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/priority"
+	"testing"
+)
+
+func TestChance(t *testing.T) {
+	cases := map[string]struct {
+		priority priority.Priority
+		chance   float64
+		expected float64
+	}{
+		"critical gets a second chance": {
+			priority: priority.Critical,
+			chance:   0.5,
+			expected: 0.75,
+		},
+		"default is unchanged": {
+			priority: priority.Default,
+			chance:   0.5,
+			expected: 0.5,
+		},
+		"sheddable must win twice": {
+			priority: priority.Sheddable,
+			chance:   0.5,
+			expected: 0.25,
+		},
+		"everything admitted": {
+			priority: priority.Sheddable,
+			chance:   1,
+			expected: 1,
+		},
+		"nothing admitted": {
+			priority: priority.Critical,
+			chance:   0,
+			expected: 0,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(priority.Chance(dt.priority, dt.chance)).Should(BeNumerically("~", dt.expected, 1e-9))
+		})
+	}
+}
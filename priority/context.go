@@ -0,0 +1,19 @@
+package priority
+
+import "context"
+
+// contextKey is unexported so only this package can set the priority on a context
+type contextKey struct{}
+
+// WithPriority returns a copy of ctx carrying the priority of the request it belongs to
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the priority set with WithPriority, or Default if none was set
+func FromContext(ctx context.Context) Priority {
+	if p, ok := ctx.Value(contextKey{}).(Priority); ok {
+		return p
+	}
+	return Default
+}
@@ -0,0 +1,30 @@
+package priority_test
+
+import (
+	"context"
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/priority"
+	"testing"
+)
+
+func TestFromContext(t *testing.T) {
+	cases := map[string]struct {
+		ctx      context.Context
+		expected priority.Priority
+	}{
+		"not set": {
+			ctx:      context.Background(),
+			expected: priority.Default,
+		},
+		"set": {
+			ctx:      priority.WithPriority(context.Background(), priority.Sheddable),
+			expected: priority.Sheddable,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(priority.FromContext(dt.ctx)).Should(Equal(dt.expected))
+		})
+	}
+}
@@ -0,0 +1,13 @@
+//go:generate go-enum --file=$GOFILE -noprefix
+
+package priority
+
+// Priority is how important a request is. When a recovering dependency can only take a fraction of the traffic,
+// more important requests should be attempted first. The zero value is Default
+/* ENUM(
+Default,
+Critical,
+Sheddable
+)
+*/
+type Priority uint8
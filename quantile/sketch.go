@@ -0,0 +1,151 @@
+package quantile
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// DefaultRelativeAccuracy estimates quantiles to within 1% of their true value
+	DefaultRelativeAccuracy = 0.01
+
+	// maxBins bounds a Sketch's memory. Once exceeded, the lowest bins are collapsed together, losing accuracy for the
+	// lowest quantiles first, which matter least for latency
+	maxBins = 2048
+
+	// minValue is the smallest value tracked accurately, smaller values are counted as zero
+	minValue = 1e-9
+)
+
+// Sketch estimates quantiles of a stream of positive values in bounded memory, using the DDSketch algorithm.
+// Each estimate is within the relative accuracy of the true quantile, such as 1% of 200ms.
+// Use NewSketch to create one. Instance is _not_ thread-safe.
+type Sketch struct {
+	gamma     float64
+	logGamma  float64
+	bins      map[int]uint64
+	zeroCount uint64
+	count     uint64
+
+	// sorted caches the indexes of the bins in order, nil when a bin was added since it was last sorted
+	sorted []int
+}
+
+// NewSketch creates a Sketch estimating quantiles to within relativeAccuracy, such as 0.01 for 1%.
+// Values outside (0, 1) use DefaultRelativeAccuracy
+func NewSketch(relativeAccuracy float64) *Sketch {
+	if relativeAccuracy <= 0 || relativeAccuracy >= 1 {
+		relativeAccuracy = DefaultRelativeAccuracy
+	}
+	gamma := (1 + relativeAccuracy) / (1 - relativeAccuracy)
+	return &Sketch{
+		gamma:    gamma,
+		logGamma: math.Log(gamma),
+		bins:     make(map[int]uint64),
+	}
+}
+
+// Add counts the value. Negative values are counted as zero
+func (s *Sketch) Add(value float64) {
+	s.count++
+	if value <= minValue {
+		s.zeroCount++
+		return
+	}
+	index := s.index(value)
+	if _, ok := s.bins[index]; !ok {
+		s.sorted = nil
+	}
+	s.bins[index]++
+	if len(s.bins) > maxBins {
+		s.collapseLowest(len(s.bins) - maxBins)
+	}
+}
+
+// Merge adds every value counted by other, which must have been created with the same relative accuracy
+func (s *Sketch) Merge(other *Sketch) {
+	s.count += other.count
+	s.zeroCount += other.zeroCount
+	for index, count := range other.bins {
+		if _, ok := s.bins[index]; !ok {
+			s.sorted = nil
+		}
+		s.bins[index] += count
+	}
+	if len(s.bins) > maxBins {
+		s.collapseLowest(len(s.bins) - maxBins)
+	}
+}
+
+// Quantile estimates the value below which q of the values fall, such as 0.99 for the 99th percentile.
+// Returns 0 if nothing was counted
+func (s *Sketch) Quantile(q float64) float64 {
+	if s.count == 0 {
+		return 0
+	}
+	if q < 0 {
+		q = 0
+	} else if q > 1 {
+		q = 1
+	}
+	rank := q * float64(s.count-1)
+	seen := float64(s.zeroCount)
+	if seen > rank {
+		return 0
+	}
+	indexes := s.sortedIndexes()
+	for _, index := range indexes {
+		seen += float64(s.bins[index])
+		if seen > rank {
+			return s.value(index)
+		}
+	}
+	return s.value(indexes[len(indexes)-1])
+}
+
+// Count is the number of values counted
+func (s *Sketch) Count() uint64 {
+	return s.count
+}
+
+// Reset forgets every value
+func (s *Sketch) Reset() {
+	s.bins = make(map[int]uint64)
+	s.sorted = nil
+	s.zeroCount = 0
+	s.count = 0
+}
+
+// index of the bin holding value
+func (s *Sketch) index(value float64) int {
+	return int(math.Ceil(math.Log(value) / s.logGamma))
+}
+
+// value is the estimate for every value in the bin, equally far in relative terms from both of its bounds
+func (s *Sketch) value(index int) float64 {
+	return 2 * math.Pow(s.gamma, float64(index)) / (s.gamma + 1)
+}
+
+// collapseLowest merges the lowest n bins, one after the other, into the lowest bin left
+func (s *Sketch) collapseLowest(n int) {
+	indexes := s.sortedIndexes()
+	for i := 0; i < n; i++ {
+		s.bins[indexes[i+1]] += s.bins[indexes[i]]
+		delete(s.bins, indexes[i])
+	}
+	s.sorted = indexes[n:]
+}
+
+// sortedIndexes of the bins, in ascending order. Do not modify the returned slice, it's cached until a bin is added
+func (s *Sketch) sortedIndexes() []int {
+	if s.sorted != nil {
+		return s.sorted
+	}
+	indexes := make([]int, 0, len(s.bins))
+	for index := range s.bins {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	s.sorted = indexes
+	return indexes
+}
@@ -0,0 +1,122 @@
+package quantile
+
+import (
+	. "github.com/onsi/gomega"
+	"math"
+	"testing"
+)
+
+func TestSketch_Quantile(t *testing.T) {
+	cases := map[string]struct {
+		values   []float64
+		q        float64
+		expected float64
+	}{
+		"empty": {
+			q:        0.5,
+			expected: 0,
+		},
+		"single value": {
+			values:   []float64{42},
+			q:        0.99,
+			expected: 42,
+		},
+		"median": {
+			values:   []float64{1, 2, 3, 4, 5},
+			q:        0.5,
+			expected: 3,
+		},
+		"maximum": {
+			values:   []float64{1, 2, 3, 4, 5},
+			q:        1,
+			expected: 5,
+		},
+		"minimum": {
+			values:   []float64{1, 2, 3, 4, 5},
+			q:        0,
+			expected: 1,
+		},
+		"zeros": {
+			values:   []float64{0, 0, 0, 10},
+			q:        0.5,
+			expected: 0,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			subject := NewSketch(0.01)
+			for _, value := range dt.values {
+				subject.Add(value)
+			}
+			g.Expect(subject.Quantile(dt.q)).Should(BeNumerically("~", dt.expected, dt.expected*0.01))
+			g.Expect(subject.Count()).Should(Equal(uint64(len(dt.values))))
+		})
+	}
+}
+
+func TestSketch_RelativeAccuracy(t *testing.T) {
+	g := NewWithT(t)
+	subject := NewSketch(0.01)
+	for value := 1; value <= 10000; value++ {
+		subject.Add(float64(value))
+	}
+	for _, q := range []float64{0.5, 0.9, 0.99, 0.999} {
+		expected := 1 + q*9999
+		g.Expect(subject.Quantile(q)).Should(BeNumerically("~", expected, expected*0.01), "quantile %v", q)
+	}
+}
+
+func TestSketch_Merge(t *testing.T) {
+	g := NewWithT(t)
+	low := NewSketch(0.01)
+	high := NewSketch(0.01)
+	for value := 1; value <= 50; value++ {
+		low.Add(float64(value))
+		high.Add(float64(value + 50))
+	}
+	low.Merge(high)
+	g.Expect(low.Count()).Should(Equal(uint64(100)))
+	g.Expect(low.Quantile(0.9)).Should(BeNumerically("~", 90, 0.9))
+}
+
+func TestSketch_BoundedMemory(t *testing.T) {
+	g := NewWithT(t)
+	subject := NewSketch(0.01)
+	for exponent := -8.0; exponent < 30; exponent += 0.001 {
+		subject.Add(math.Pow(10, exponent))
+	}
+	g.Expect(len(subject.bins)).Should(BeNumerically("<=", maxBins))
+	g.Expect(subject.Quantile(0.99)).Should(BeNumerically("~", math.Pow(10, 29.62), math.Pow(10, 29.62)*0.02))
+}
+
+func TestSketch_MergeBoundedMemory(t *testing.T) {
+	g := NewWithT(t)
+	low := NewSketch(0.01)
+	high := NewSketch(0.01)
+	for exponent := 0.0; exponent < 8; exponent += 0.001 {
+		low.Add(math.Pow(10, exponent))
+		high.Add(math.Pow(10, exponent+8))
+	}
+	low.Merge(high)
+	g.Expect(len(low.bins)).Should(BeNumerically("<=", maxBins))
+	g.Expect(low.Quantile(0.99)).Should(BeNumerically("~", math.Pow(10, 15.84), math.Pow(10, 15.84)*0.02))
+}
+
+func TestSketch_QuantileAfterAddingLowerValues(t *testing.T) {
+	g := NewWithT(t)
+	subject := NewSketch(0.01)
+	subject.Add(100)
+	g.Expect(subject.Quantile(0)).Should(BeNumerically("~", 100, 1))
+	subject.Add(10)
+	g.Expect(subject.Quantile(0)).Should(BeNumerically("~", 10, 0.1))
+}
+
+func TestSketch_Reset(t *testing.T) {
+	g := NewWithT(t)
+	subject := NewSketch(0.01)
+	subject.Add(10)
+	subject.Reset()
+	g.Expect(subject.Count()).Should(BeZero())
+	g.Expect(subject.Quantile(0.5)).Should(BeZero())
+}
@@ -0,0 +1,118 @@
+package quantile
+
+import "time"
+
+// Window estimates quantiles of the values added over a sliding window of time. The window is divided into buckets,
+// each with its own Sketch, and values expire a whole bucket at a time, just like slidingWindow.Counter.
+// Use NewWindow to create one. Instance is _not_ thread-safe.
+type Window struct {
+	relativeAccuracy float64
+	bucketWidth      time.Duration
+	buckets          []bucket
+
+	// merged holds the values of every bucket within the window ending in mergedEpoch, nil when it must be merged
+	// again. Add keeps it up to date until a bucket is cleared or the window moves on
+	merged      *Sketch
+	mergedEpoch int64
+}
+
+// bucket holds the values added during one bucketWidth of time
+type bucket struct {
+	// epoch is which bucketWidth of time, counted from the unix epoch, this bucket holds
+	epoch  int64
+	sketch *Sketch
+}
+
+// NewWindow creates a Window estimating quantiles of values over window, divided into numberOfBuckets buckets, to
+// within relativeAccuracy, see NewSketch
+func NewWindow(window time.Duration, numberOfBuckets int, relativeAccuracy float64) *Window {
+	if numberOfBuckets < 1 {
+		numberOfBuckets = 1
+	}
+	bucketWidth := window / time.Duration(numberOfBuckets)
+	if bucketWidth <= 0 {
+		bucketWidth = 1
+	}
+	buckets := make([]bucket, numberOfBuckets)
+	for i := range buckets {
+		buckets[i].sketch = NewSketch(relativeAccuracy)
+	}
+	return &Window{
+		relativeAccuracy: relativeAccuracy,
+		bucketWidth:      bucketWidth,
+		buckets:          buckets,
+	}
+}
+
+// Add counts value in the window at the time now
+func (w *Window) Add(now time.Time, value float64) {
+	b := w.bucketFor(now)
+	b.sketch.Add(value)
+	if w.merged != nil && w.isWithin(b.epoch, w.mergedEpoch) {
+		w.merged.Add(value)
+	}
+}
+
+// Sketch holds the values within the window ending at now. The buckets are only merged again when the window moves
+// on to another bucket, so the Sketch is shared with the Window: do not modify it and expect Add to change it
+func (w *Window) Sketch(now time.Time) *Sketch {
+	currentEpoch := w.epoch(now)
+	if w.merged == nil || w.mergedEpoch != currentEpoch {
+		w.merged = w.merge(currentEpoch)
+		w.mergedEpoch = currentEpoch
+	}
+	return w.merged
+}
+
+// merge the buckets within the window ending in currentEpoch into a new Sketch
+func (w *Window) merge(currentEpoch int64) *Sketch {
+	merged := NewSketch(w.relativeAccuracy)
+	for _, b := range w.buckets {
+		if w.isWithin(b.epoch, currentEpoch) {
+			merged.Merge(b.sketch)
+		}
+	}
+	return merged
+}
+
+// isWithin is true if the bucket holding epoch is within the window ending in currentEpoch
+func (w *Window) isWithin(epoch int64, currentEpoch int64) bool {
+	oldestEpoch := currentEpoch - int64(len(w.buckets)) + 1
+	return epoch >= oldestEpoch && epoch <= currentEpoch
+}
+
+// Quantile estimates the q quantile of the values within the window ending at now, see Sketch.Quantile
+func (w *Window) Quantile(now time.Time, q float64) float64 {
+	return w.Sketch(now).Quantile(q)
+}
+
+// Reset forgets every value
+func (w *Window) Reset() {
+	for i := range w.buckets {
+		w.buckets[i].epoch = 0
+		w.buckets[i].sketch.Reset()
+	}
+	w.merged = nil
+}
+
+// bucketFor returns the bucket holding now, clearing it if it was holding an older time
+func (w *Window) bucketFor(now time.Time) *bucket {
+	epoch := w.epoch(now)
+	index := epoch % int64(len(w.buckets))
+	if index < 0 {
+		index += int64(len(w.buckets))
+	}
+	b := &w.buckets[index]
+	if b.epoch != epoch {
+		b.epoch = epoch
+		b.sketch.Reset()
+		// the merged sketch still holds the values that were just cleared
+		w.merged = nil
+	}
+	return b
+}
+
+// epoch is the number of bucketWidths since the unix epoch
+func (w *Window) epoch(now time.Time) int64 {
+	return now.UnixNano() / int64(w.bucketWidth)
+}
@@ -0,0 +1,98 @@
+package quantile
+
+import (
+	. "github.com/onsi/gomega"
+	"testing"
+	"time"
+)
+
+func TestWindow_Quantile(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	type add struct {
+		at    time.Duration
+		value float64
+	}
+	cases := map[string]struct {
+		adds          []add
+		quantileAt    time.Duration
+		expected      float64
+		expectedCount uint64
+	}{
+		"empty": {},
+		"within window": {
+			adds: []add{
+				{at: 0, value: 100},
+				{at: 5 * time.Second, value: 200},
+				{at: 9 * time.Second, value: 300},
+			},
+			quantileAt:    9 * time.Second,
+			expected:      300,
+			expectedCount: 3,
+		},
+		"oldest bucket expired": {
+			adds: []add{
+				{at: 0, value: 300},
+				{at: 5 * time.Second, value: 200},
+			},
+			quantileAt:    10 * time.Second,
+			expected:      200,
+			expectedCount: 1,
+		},
+		"everything expired": {
+			adds: []add{
+				{at: 0, value: 300},
+			},
+			quantileAt: time.Minute,
+		},
+		"reused bucket is cleared": {
+			adds: []add{
+				{at: 0, value: 300},
+				{at: 10 * time.Second, value: 100},
+			},
+			quantileAt:    10 * time.Second,
+			expected:      100,
+			expectedCount: 1,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			subject := NewWindow(10*time.Second, 2, 0.01)
+			for _, a := range dt.adds {
+				subject.Add(start.Add(a.at), a.value)
+			}
+			sketch := subject.Sketch(start.Add(dt.quantileAt))
+			g.Expect(sketch.Quantile(1)).Should(BeNumerically("~", dt.expected, dt.expected*0.01))
+			g.Expect(sketch.Count()).Should(Equal(dt.expectedCount))
+		})
+	}
+}
+
+func TestWindow_Sketch(t *testing.T) {
+	g := NewWithT(t)
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	subject := NewWindow(10*time.Second, 2, 0.01)
+	subject.Add(now, 100)
+	g.Expect(subject.Sketch(now).Count()).Should(Equal(uint64(1)))
+	subject.Add(now, 200)
+	g.Expect(subject.Sketch(now).Count()).Should(Equal(uint64(2)))
+
+	now = now.Add(5 * time.Second)
+	subject.Add(now, 300)
+	g.Expect(subject.Sketch(now).Count()).Should(Equal(uint64(3)))
+
+	now = now.Add(5 * time.Second)
+	g.Expect(subject.Sketch(now).Count()).Should(Equal(uint64(1)))
+	subject.Add(now, 400)
+	g.Expect(subject.Sketch(now).Count()).Should(Equal(uint64(2)))
+	g.Expect(subject.Sketch(now).Quantile(1)).Should(BeNumerically("~", 400, 4))
+}
+
+func TestWindow_Reset(t *testing.T) {
+	g := NewWithT(t)
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	subject := NewWindow(10*time.Second, 2, 0.01)
+	subject.Add(now, 100)
+	subject.Reset()
+	g.Expect(subject.Quantile(now, 0.5)).Should(BeZero())
+}
@@ -0,0 +1,45 @@
+package shadow
+
+import "sync/atomic"
+
+// Decisions counts how many calls a breaker admitted and how many it rejected, or in shadow mode, would have rejected
+type Decisions struct {
+	Admitted uint64
+	Rejected uint64
+}
+
+// Total is the number of calls decided
+func (d Decisions) Total() uint64 {
+	return d.Admitted + d.Rejected
+}
+
+// RejectionRate is the fraction of calls rejected, from 0 to 1. 0 if no calls were decided
+func (d Decisions) RejectionRate() float64 {
+	if d.Total() == 0 {
+		return 0
+	}
+	return float64(d.Rejected) / float64(d.Total())
+}
+
+// Counter records Decisions. The zero value is ready to use and it's safe to use concurrently
+type Counter struct {
+	admitted uint64
+	rejected uint64
+}
+
+// Record counts a single decision
+func (c *Counter) Record(admitted bool) {
+	if admitted {
+		atomic.AddUint64(&c.admitted, 1)
+	} else {
+		atomic.AddUint64(&c.rejected, 1)
+	}
+}
+
+// Decisions copies the counts recorded so far
+func (c *Counter) Decisions() Decisions {
+	return Decisions{
+		Admitted: atomic.LoadUint64(&c.admitted),
+		Rejected: atomic.LoadUint64(&c.rejected),
+	}
+}
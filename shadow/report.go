@@ -0,0 +1,72 @@
+package shadow
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Reporter is a breaker that counts its decisions. twoStateCircuit.Breaker and threeStateCircuit.Breaker both
+// implement this
+type Reporter interface {
+	Decisions() Decisions
+}
+
+// Report compares the decisions of a live breaker to those of its shadows
+type Report struct {
+	Live    Decisions
+	Shadows []Comparison
+}
+
+// Comparison is how a single shadow's decisions differ from the live breaker's.
+// Shadows only see the calls the live breaker admitted, the calls it rejected were never made, so there is no outcome
+// to show the shadows. What a shadow would have done with those calls is unknown, so the comparison is limited to the
+// calls the live breaker admitted.
+type Comparison struct {
+	Name      string
+	Decisions Decisions
+
+	// RejectedOfLiveAdmitted is how many of the calls the live breaker admitted the shadow would have rejected
+	RejectedOfLiveAdmitted uint64
+
+	// RejectedOfLiveAdmittedRate is RejectedOfLiveAdmitted as a fraction of the calls the live breaker admitted,
+	// from 0 to 1. 0 if the live breaker admitted no calls
+	RejectedOfLiveAdmittedRate float64
+}
+
+// Compare the decisions of the live breaker with each named shadow. Shadows are reported in order of name
+func Compare(live Reporter, shadows map[string]Reporter) Report {
+	report := Report{
+		Live:    live.Decisions(),
+		Shadows: make([]Comparison, 0, len(shadows)),
+	}
+	for name, shadow := range shadows {
+		decisions := shadow.Decisions()
+		comparison := Comparison{
+			Name:                   name,
+			Decisions:              decisions,
+			RejectedOfLiveAdmitted: decisions.Rejected,
+		}
+		if report.Live.Admitted != 0 {
+			comparison.RejectedOfLiveAdmittedRate = float64(decisions.Rejected) / float64(report.Live.Admitted)
+		}
+		report.Shadows = append(report.Shadows, comparison)
+	}
+	sort.Slice(report.Shadows, func(i, j int) bool {
+		return report.Shadows[i].Name < report.Shadows[j].Name
+	})
+	return report
+}
+
+// String summarizes the report, one line per breaker, such as for logging. Shadows are only compared on the calls the
+// live breaker admitted
+func (r Report) String() string {
+	var builder strings.Builder
+	_, _ = fmt.Fprintf(&builder, "live: admitted %d, rejected %d (%.1f%%)",
+		r.Live.Admitted, r.Live.Rejected, r.Live.RejectionRate()*100)
+	for _, shadow := range r.Shadows {
+		_, _ = fmt.Fprintf(&builder, "\n%s: of the %d calls live admitted, would have rejected %d (%.1f%%)",
+			shadow.Name, r.Live.Admitted, shadow.RejectedOfLiveAdmitted, shadow.RejectedOfLiveAdmittedRate*100)
+	}
+	return builder.String()
+}
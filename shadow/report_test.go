@@ -0,0 +1,67 @@
+package shadow
+
+import (
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+// fixedReporter always reports the same decisions
+type fixedReporter Decisions
+
+func (f fixedReporter) Decisions() Decisions {
+	return Decisions(f)
+}
+
+func TestCompare(t *testing.T) {
+	g := NewWithT(t)
+	report := Compare(fixedReporter{Admitted: 90, Rejected: 10}, map[string]Reporter{
+		"tighter": fixedReporter{Admitted: 60, Rejected: 30},
+		"looser":  fixedReporter{Admitted: 90},
+	})
+	g.Expect(report.Live).Should(Equal(Decisions{Admitted: 90, Rejected: 10}))
+	g.Expect(report.Shadows).Should(HaveLen(2))
+	g.Expect(report.Shadows[0].Name).Should(Equal("looser"))
+	g.Expect(report.Shadows[0].RejectedOfLiveAdmitted).Should(Equal(uint64(0)))
+	g.Expect(report.Shadows[1].Name).Should(Equal("tighter"))
+	g.Expect(report.Shadows[1].RejectedOfLiveAdmitted).Should(Equal(uint64(30)))
+	g.Expect(report.Shadows[1].RejectedOfLiveAdmittedRate).Should(BeNumerically("~", 1.0/3, 0.0001))
+	g.Expect(report.String()).Should(Equal("live: admitted 90, rejected 10 (10.0%)\n" +
+		"looser: of the 90 calls live admitted, would have rejected 0 (0.0%)\n" +
+		"tighter: of the 90 calls live admitted, would have rejected 30 (33.3%)"))
+}
+
+func TestCompare_NothingAdmitted(t *testing.T) {
+	g := NewWithT(t)
+	report := Compare(fixedReporter{Rejected: 10}, map[string]Reporter{"tighter": fixedReporter{}})
+	g.Expect(report.Shadows[0].RejectedOfLiveAdmittedRate).Should(Equal(0.0))
+}
+
+func TestDecisions_RejectionRate(t *testing.T) {
+	cases := map[string]struct {
+		decisions Decisions
+		expected  float64
+	}{
+		"none decided": {
+			expected: 0,
+		},
+		"some rejected": {
+			decisions: Decisions{Admitted: 3, Rejected: 1},
+			expected:  0.25,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(dt.decisions.RejectionRate()).Should(Equal(dt.expected))
+		})
+	}
+}
+
+func TestCounter(t *testing.T) {
+	g := NewWithT(t)
+	var subject Counter
+	subject.Record(true)
+	subject.Record(true)
+	subject.Record(false)
+	g.Expect(subject.Decisions()).Should(Equal(Decisions{Admitted: 2, Rejected: 1}))
+}
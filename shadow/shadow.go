@@ -0,0 +1,21 @@
+package shadow
+
+import "time"
+
+// Breaker is a breaker that can be attached to a live breaker as a shadow, such as a twoStateCircuit.Breaker or
+// threeStateCircuit.Breaker with Shadow set in its Opts. Shadows should be in shadow mode, otherwise they stop seeing
+// outcomes while they are open. Shadows only see the calls the live breaker attempts.
+type Breaker interface {
+	// Record decides whether to admit a call that already finished and records its outcome and latency as if it
+	// had been made through the breaker
+	Record(outcome error, latency time.Duration)
+}
+
+// Forward replays the outcome and latency of a call made through the live breaker to each of its shadows, so they
+// see exactly the same results without the call being made again. Shadows are told on the live call's goroutine, so
+// a shadow's OnStateChange and OnEvent channels must be buffered or drained, or they will block the live call
+func Forward(shadows []Breaker, outcome error, latency time.Duration) {
+	for _, shadow := range shadows {
+		shadow.Record(outcome, latency)
+	}
+}
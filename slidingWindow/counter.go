@@ -0,0 +1,86 @@
+package slidingWindow
+
+import "time"
+
+// Counter sums values added over a sliding window of time. The window is divided into buckets, values expire a whole
+// bucket at a time, so more buckets track the window more smoothly at the cost of memory.
+// Use New to create one. Instance is _not_ thread-safe.
+type Counter struct {
+	bucketWidth time.Duration
+	buckets     []bucket
+}
+
+// bucket holds the sum of the values added during one bucketWidth of time
+type bucket struct {
+	// epoch is which bucketWidth of time, counted from the unix epoch, this bucket holds
+	epoch int64
+	value float64
+}
+
+// New creates a Counter summing values over window, divided into numberOfBuckets buckets
+func New(window time.Duration, numberOfBuckets int) *Counter {
+	if numberOfBuckets < 1 {
+		numberOfBuckets = 1
+	}
+	bucketWidth := window / time.Duration(numberOfBuckets)
+	if bucketWidth <= 0 {
+		bucketWidth = 1
+	}
+	return &Counter{
+		bucketWidth: bucketWidth,
+		buckets:     make([]bucket, numberOfBuckets),
+	}
+}
+
+// Add adds value to the window at the time now. Values added at a time whose bucket was already reused by a later
+// time have left the window, so are dropped
+func (c *Counter) Add(now time.Time, value float64) {
+	current := c.bucketFor(now)
+	if current == nil {
+		return
+	}
+	current.value += value
+}
+
+// Sum is the total of the values added within the window ending at now
+func (c *Counter) Sum(now time.Time) (sum float64) {
+	currentEpoch := c.epoch(now)
+	oldestEpoch := currentEpoch - int64(len(c.buckets)) + 1
+	for _, b := range c.buckets {
+		if b.epoch >= oldestEpoch && b.epoch <= currentEpoch {
+			sum += b.value
+		}
+	}
+	return
+}
+
+// Reset forgets every value
+func (c *Counter) Reset() {
+	for i := range c.buckets {
+		c.buckets[i] = bucket{}
+	}
+}
+
+// bucketFor returns the bucket holding now, clearing it if it was holding an older time. nil if it is holding a later
+// time
+func (c *Counter) bucketFor(now time.Time) *bucket {
+	epoch := c.epoch(now)
+	index := epoch % int64(len(c.buckets))
+	if index < 0 {
+		index += int64(len(c.buckets))
+	}
+	b := &c.buckets[index]
+	if b.epoch > epoch {
+		return nil
+	}
+	if b.epoch != epoch {
+		b.epoch = epoch
+		b.value = 0
+	}
+	return b
+}
+
+// epoch is the number of bucketWidths since the unix epoch
+func (c *Counter) epoch(now time.Time) int64 {
+	return now.UnixNano() / int64(c.bucketWidth)
+}
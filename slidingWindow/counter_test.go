@@ -0,0 +1,85 @@
+package slidingWindow
+
+import (
+	. "github.com/onsi/gomega"
+	"testing"
+	"time"
+)
+
+func TestCounter_Sum(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	type add struct {
+		at    time.Duration
+		value float64
+	}
+	cases := map[string]struct {
+		adds     []add
+		sumAt    time.Duration
+		expected float64
+	}{
+		"empty": {
+			sumAt:    0,
+			expected: 0,
+		},
+		"within window": {
+			adds: []add{
+				{at: 0, value: 1},
+				{at: 5 * time.Second, value: 2},
+				{at: 9 * time.Second, value: 3},
+			},
+			sumAt:    9 * time.Second,
+			expected: 6,
+		},
+		"oldest bucket expired": {
+			adds: []add{
+				{at: 0, value: 1},
+				{at: 5 * time.Second, value: 2},
+			},
+			sumAt:    10 * time.Second,
+			expected: 2,
+		},
+		"everything expired": {
+			adds: []add{
+				{at: 0, value: 1},
+				{at: 5 * time.Second, value: 2},
+			},
+			sumAt:    1 * time.Minute,
+			expected: 0,
+		},
+		"reused bucket is cleared": {
+			adds: []add{
+				{at: 0, value: 1},
+				{at: 10 * time.Second, value: 2},
+			},
+			sumAt:    10 * time.Second,
+			expected: 2,
+		},
+		"adding to a reused bucket is dropped": {
+			adds: []add{
+				{at: 10 * time.Second, value: 2},
+				{at: 0, value: 1},
+			},
+			sumAt:    10 * time.Second,
+			expected: 2,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			subject := New(10*time.Second, 10)
+			for _, a := range dt.adds {
+				subject.Add(start.Add(a.at), a.value)
+			}
+			g.Expect(subject.Sum(start.Add(dt.sumAt))).Should(Equal(dt.expected))
+		})
+	}
+}
+
+func TestCounter_Reset(t *testing.T) {
+	g := NewWithT(t)
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	subject := New(10*time.Second, 10)
+	subject.Add(now, 5)
+	subject.Reset()
+	g.Expect(subject.Sum(now)).Should(Equal(0.0))
+}
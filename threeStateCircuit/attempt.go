@@ -0,0 +1,12 @@
+package threeStateCircuit
+
+import "github.com/wojnosystems/go-circuit-breaker/priority"
+
+// Attempt describes a request to UseAttempt, which the Sampler can consider when deciding whether to attempt it
+type Attempt struct {
+	// Priority of the request
+	Priority priority.Priority
+
+	// Key identifies what the request is for, such as the route or the user
+	Key string
+}
@@ -0,0 +1,83 @@
+package threeStateCircuit
+
+import (
+	"context"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/priority"
+	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit/state"
+	"sync/atomic"
+	"time"
+)
+
+// recordingSampler remembers every SampleContext it was asked about
+type recordingSampler struct {
+	contexts []SampleContext
+}
+
+func (r *recordingSampler) Sample(sampleContext SampleContext) bool {
+	r.contexts = append(r.contexts, sampleContext)
+	return true
+}
+
+var _ = Describe("Breaker.UseAttempt", func() {
+	var (
+		breaker *Breaker
+		sampler *recordingSampler
+		now     time.Time
+	)
+	BeforeEach(func() {
+		now = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		sampler = &recordingSampler{}
+		breaker = New(Opts{
+			Sampler:                            sampler,
+			NumberOfSuccessesInHalfOpenToClose: 10,
+			nowFactory: func() time.Time {
+				return now
+			},
+		})
+		breaker.state = state.HalfOpen
+		breaker.halfOpenAt = now.Add(-5 * time.Second)
+		breaker.halfOpenSuccesses = 3
+		breaker.lastError = trippingError.Err
+	})
+	It("describes the attempt to the sampler", func() {
+		_ = breaker.UseAttempt(Attempt{Priority: priority.Critical, Key: "/users"}, func() error {
+			return nil
+		})
+		Expect(sampler.contexts).Should(Equal([]SampleContext{
+			{
+				TimeInHalfOpen: 5 * time.Second,
+				Successes:      3,
+				Priority:       priority.Critical,
+				Key:            "/users",
+			},
+		}))
+	})
+	It("counts sampled requests in flight", func() {
+		_ = breaker.Use(func() error {
+			return breaker.Use(func() error {
+				return nil
+			})
+		})
+		Expect(sampler.contexts[1].InFlight).Should(Equal(int64(1)))
+		Expect(atomic.LoadInt64(&breaker.halfOpenInFlight)).Should(Equal(int64(0)))
+	})
+	It("asks the sampler once per request, whatever its priority", func() {
+		for _, p := range []priority.Priority{priority.Sheddable, priority.Default, priority.Critical} {
+			_ = breaker.UseAttempt(Attempt{Priority: p}, func() error {
+				return nil
+			})
+		}
+		Expect(sampler.contexts).Should(HaveLen(3))
+		Expect(sampler.contexts[0].Priority).Should(Equal(priority.Sheddable))
+		Expect(sampler.contexts[2].Priority).Should(Equal(priority.Critical))
+	})
+	It("reads the priority from the context", func() {
+		_ = breaker.UseContext(priority.WithPriority(context.Background(), priority.Critical), func() error {
+			return nil
+		})
+		Expect(sampler.contexts).Should(HaveLen(1))
+		Expect(sampler.contexts[0].Priority).Should(Equal(priority.Critical))
+	})
+})
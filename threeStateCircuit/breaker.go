@@ -1,10 +1,16 @@
 package threeStateCircuit
 
 import (
+	"context"
+	"github.com/wojnosystems/go-circuit-breaker/priority"
+	"github.com/wojnosystems/go-circuit-breaker/shadow"
 	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit/state"
+	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit/warmUpCurve"
 	"github.com/wojnosystems/go-circuit-breaker/tripping"
 	"github.com/wojnosystems/go-time-factory/timeFactory"
+	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -12,6 +18,22 @@ type Opts struct {
 	// TripDecider is consulted each time a tripping error occurs.
 	TripDecider tripping.Decider
 
+	// Recorder, if set, is used instead of the TripDecider. It observes the outcome and latency of every call and is
+	// reset each time the breaker transitions
+	Recorder tripping.Recorder
+
+	// Classifier decides which errors returned by callbacks count against the breaker. Leave nil to only count
+	// tripping errors
+	Classifier *tripping.Classifier
+
+	// CategoryRecorders give each category of tripping.Error its own Recorder, such as to trip on 3 connection
+	// refusals in a row. Failures in other categories are told to Recorder, see tripping.ByCategory
+	CategoryRecorders map[tripping.Category]tripping.Recorder
+
+	// CostFunc, if set, computes the cost of each failure from its error, latency and category, replacing the cost it
+	// was created with. See tripping.LatencyCost and circuitHTTP.DefaultCost
+	CostFunc tripping.CostFunc
+
 	// OpenDuration is how long to stay in the OpenState before closing again
 	OpenDuration time.Duration
 
@@ -20,13 +42,62 @@ type Opts struct {
 	// Do NOT close this channel or a panic will occur
 	OnStateChange chan<- state.State
 
+	// Shadow, if true, runs the breaker in shadow mode: it tracks its state and emits transitions and events exactly as
+	// usual, but never rejects a call. Calls it would have rejected are attempted, but their outcomes are not
+	// recorded, as they would never have been seen. Use Decisions to find out how many calls it would have rejected
+	Shadow bool
+
+	// Shadows see the outcome and latency of every call this breaker attempts, so a new configuration can be compared
+	// against the live one using shadow.Compare. Set Shadow on each of them. They are told during the call, so buffer
+	// or drain their OnStateChange and OnEvent channels
+	Shadows []shadow.Breaker
+
+	// OnEvent if set, will emit an Event each time the breaker transitions, when it finishes warming up and when it
+	// notices the Recorder switched to another tripping.Regime.
+	// Leave as nil to avoid listening to events
+	// Do NOT close this channel or a panic will occur
+	OnEvent chan<- Event
+
 	// HalfOpenSampler tells the circuit breaker which requests to reject and which to attempt while in the half-open state
 	HalfOpenSampler ShouldSample
 
+	// Sampler, if set, is used instead of HalfOpenSampler. It's told about each request, such as its priority and how
+	// many sampled requests are in flight, so it can decide which to attempt while in the half-open state.
+	// It's asked exactly once per request and decides how the request's priority is taken into account
+	Sampler Sampler
+
 	// NumberOfSuccessesInHalfOpenToClose is the number of times the requests need to succeed while in the Half-Open state
 	// in order to transition back to the closed state. Any error in the half-open state, will reset it back to the open state
 	NumberOfSuccessesInHalfOpenToClose uint64
 
+	// HealthCheck, if set, is run in the background every HealthCheckInterval while the breaker is Open or HalfOpen.
+	// Each successful check counts towards NumberOfSuccessesInHalfOpenToClose and any failed check re-opens the breaker.
+	// While a HealthCheck is set, users' requests are never sampled: they are rejected until the checks close the
+	// breaker. Call Close to stop the checks once the breaker is no longer needed
+	HealthCheck HealthCheck
+
+	// HealthCheckInterval is how long to wait between checks. Defaults to 1 second
+	HealthCheckInterval time.Duration
+
+	// HealthCheckTimeout is how long each check may take before its context is cancelled. Defaults to HealthCheckInterval
+	HealthCheckTimeout time.Duration
+
+	// WarmUpDuration, if set, ramps traffic back up after the breaker closes instead of admitting it all at once.
+	// Over this duration, the chance of attempting a request grows from WarmUpStartChance to 1 along the WarmUpCurve.
+	// Requests that are not attempted are rejected with the last error, and any tripping error while warming up
+	// re-opens the breaker immediately without consulting the TripDecider
+	WarmUpDuration time.Duration
+
+	// WarmUpStartChance is the chance of attempting a request as soon as the breaker closes. Defaults to 0.1
+	WarmUpStartChance float64
+
+	// WarmUpCurve is how the chance grows while warming up. Defaults to Linear
+	WarmUpCurve warmUpCurve.Curve
+
+	// WarmUpRandomSource decides which requests are attempted while warming up. Leave nil to use math.Rand seeded
+	// with the current time. The source does not need to be thread-safe
+	WarmUpRandomSource rand.Source
+
 	// nowFactory allows the current time to be simulated
 	nowFactory timeFactory.Now
 }
@@ -37,6 +108,12 @@ type mutableState struct {
 	openExpiresAt     time.Time
 	halfOpenAt        time.Time
 	halfOpenSuccesses uint64
+	closedAt          time.Time
+	warmingUp         bool
+	tripExplanation   tripping.Explanation
+
+	// regime is the name of the Recorder's active tripping.Regime when last noticed, empty if it is not scheduled
+	regime string
 }
 
 // Breaker is a live circuit breaker that only has 2 states: closed and open.
@@ -45,15 +122,49 @@ type Breaker struct {
 	opts Opts
 	mu   sync.RWMutex
 	mutableState
+
+	// stopProber cancels the HealthCheck prober, nil if there is no prober
+	stopProber context.CancelFunc
+	proberDone sync.WaitGroup
+
+	randomMu sync.Mutex
+	random   *rand.Rand
+
+	// halfOpenInFlight is the number of sampled requests that have not completed, only access atomically
+	halfOpenInFlight int64
+
+	decisions shadow.Counter
+
+	// categoryFailures counts every recorded failure by category, guarded by mu
+	categoryFailures map[tripping.Category]uint64
 }
 
 func New(opts Opts) *Breaker {
-	return &Breaker{
+	b := &Breaker{
 		opts: opts,
 		mutableState: mutableState{
 			state: state.Closed,
 		},
+		categoryFailures: make(map[tripping.Category]uint64),
+	}
+	if b.opts.Recorder == nil {
+		// follows the TripDecider, so it can still be replaced after the breaker is created
+		b.opts.Recorder = tripping.RecorderFor(&b.opts.TripDecider)
+	}
+	if len(b.opts.CategoryRecorders) > 0 {
+		b.opts.Recorder = tripping.ByCategory(b.opts.Recorder, b.opts.CategoryRecorders)
+	}
+	tripping.SetClock(b.opts.Recorder, b.now)
+	if regime, ok := tripping.FindActiveRegime(b.opts.Recorder); ok {
+		b.regime = regime.Name
 	}
+	if opts.HealthCheck != nil {
+		b.startProber()
+	}
+	if opts.WarmUpDuration > 0 {
+		b.startWarmUps()
+	}
+	return b
 }
 
 // Use the breaker, if Closed, attempt the callback, if Open, return the last error. After being in the open state
@@ -62,37 +173,92 @@ func New(opts Opts) *Breaker {
 // the breaker will transition back to the Closed state, in which all requests will be attempted.
 // If, when in the HalfOpen state, an error occurs, the breaker will re-enter the Open state.
 //
-// callback can return any error, but only errors wrapped in tripping.New() will be counted when deciding whether to trip
-// the breaker or transition back to the Open state from the HalfOpen state. All other errors will be returned without
-// contributing to the breaker's error limits.
+// callback can return any error, but only tripping errors and the errors the Classifier records will be counted when
+// deciding whether to trip the breaker or transition back to the Open state from the HalfOpen state. All other errors
+// will be returned without contributing to the breaker's error limits.
 // When in the Open or HalfOpen state, Use will always return the error that tripped the breaker. If, while in the
 // HalfOpen state, the request is sampled, you could see a new error or nil, depending on whether the request
 // was allowed to run.
 // callbacks can be called concurrently. Use will not block while the callback is being executed.
 // This does mean that sometimes, callbacks will be called while the breaker has already tripped.
 func (b *Breaker) Use(callback func() error) error {
-	stateCopy, now := b.copyCurrentState()
-	if stateCopy.state == state.Open {
-		if stateCopy.openExpiresAt.After(now) {
-			// still in the open state, not expired
-			return stateCopy.lastError
-		}
+	return b.UseAttempt(Attempt{}, callback)
+}
 
-		stateCopy = b.transitionToHalfOpenIfShould()
+// UseContext works exactly like UseAttempt, with the priority set on ctx using priority.WithPriority
+func (b *Breaker) UseContext(ctx context.Context, callback func() error) error {
+	return b.UseAttempt(Attempt{Priority: priority.FromContext(ctx)}, callback)
+}
+
+// UseAttempt works exactly like Use, but describes the request so the Sampler can take its priority and key into
+// account. While warming up, higher priority requests are more likely to be attempted, see priority.Chance.
+// While in the half-open state, priority is left to the Sampler
+func (b *Breaker) UseAttempt(attempt Attempt, callback func() error) error {
+	admitted, sampled, rejectedErr := b.admit(attempt)
+	b.decisions.Record(admitted)
+	if !admitted && !b.opts.Shadow {
+		return rejectedErr
+	}
+	if sampled {
+		atomic.AddInt64(&b.halfOpenInFlight, 1)
+		defer atomic.AddInt64(&b.halfOpenInFlight, -1)
 	}
 
-	if stateCopy.state == state.HalfOpen {
-		if !b.opts.HalfOpenSampler.ShouldSample(b.opts.nowFactory.Get().Sub(stateCopy.halfOpenAt)) {
-			return b.lastError
-		}
+	// at this point, we have either returned, we're in the closed state or sampled, or we're in shadow mode
+	start := b.opts.nowFactory.Get()
+	err := callback()
+	return b.record(admitted, err, b.opts.nowFactory.Get().Sub(start))
+}
+
+// Record works exactly like Use, but for a call that was already made, such as when this breaker is the shadow of
+// another, see shadow.Forward
+func (b *Breaker) Record(outcome error, latency time.Duration) {
+	admitted, _, _ := b.admit(Attempt{})
+	b.decisions.Record(admitted)
+	if !admitted && !b.opts.Shadow {
+		return
 	}
+	_ = b.record(admitted, outcome, latency)
+}
 
-	// at this point, we have either returned or we're in the closed state
+// UseForced calls callback even if the breaker would reject it, such as when a balancer may not eject any more
+// backends, and records its outcome. A forced call probes the dependency like a sampled call: unless a HealthCheck
+// decides when to close, a success counts towards NumberOfSuccessesInHalfOpenToClose, entering HalfOpen first if Open.
+// A failure is recorded as usual, re-opening the breaker if it is HalfOpen
+func (b *Breaker) UseForced(callback func() error) error {
+	b.decisions.Record(true)
+	start := b.opts.nowFactory.Get()
 	err := callback()
-	if !tripping.IsTripping(err) {
-		b.mu.RLock()
-		currentState := b.state
-		b.mu.RUnlock()
+	latency := b.opts.nowFactory.Get().Sub(start)
+	if tripping.IsUnrecorded(err) {
+		return tripping.Strip(err)
+	}
+	shadow.Forward(b.opts.Shadows, err, latency)
+	trippingError := b.opts.Classifier.Classify(err)
+	if trippingError == nil {
+		if b.recordSuccess(latency) != state.Closed && b.opts.HealthCheck == nil {
+			b.recordProbeSuccess()
+		}
+		return err
+	}
+	b.recordErrorAndTransitionToOpenIfShould(b.opts.CostFunc.Weigh(trippingError, latency), latency)
+	return tripping.Strip(err)
+}
+
+// record the outcome of an attempted call and return the error UseAttempt should return
+func (b *Breaker) record(admitted bool, err error, latency time.Duration) error {
+	if tripping.IsUnrecorded(err) {
+		// says nothing about the health of the dependency, so neither the breaker nor its shadows see it
+		return tripping.Strip(err)
+	}
+	shadow.Forward(b.opts.Shadows, err, latency)
+	trippingError := b.opts.Classifier.Classify(err)
+	if trippingError == nil {
+		if !admitted {
+			// shadow mode, the breaker would have rejected this call so would never have seen the result
+			return err
+		}
+		currentState := b.recordSuccess(latency)
 		if currentState == state.HalfOpen {
 			b.recordSuccessAndTransitionToClosedIfShould()
 		}
@@ -100,20 +266,61 @@ func (b *Breaker) Use(callback func() error) error {
 		return err
 	}
 
-	trippingError := err.(*tripping.Error)
-	unwrappedError := err.(*tripping.Error).Err
+	unwrappedError := tripping.Strip(err)
+	if !admitted {
+		// shadow mode, the breaker would have rejected this call so would never have seen the error
+		return unwrappedError
+	}
 
 	// we encountered an error, we need to count this against our error threshold and transition if need be
-	b.recordErrorAndTransitionToOpenIfShould(trippingError)
+	b.recordErrorAndTransitionToOpenIfShould(b.opts.CostFunc.Weigh(trippingError, latency), latency)
 	return unwrappedError
 }
 
+// admit returns true if the call should be attempted, otherwise it returns the error to reject it with.
+// sampled is true if the call was admitted while in the half-open state
+func (b *Breaker) admit(attempt Attempt) (admitted bool, sampled bool, rejectedErr error) {
+	stateCopy, now := b.copyCurrentState()
+	if b.opts.HealthCheck != nil && stateCopy.state != state.Closed {
+		// the prober decides when to close, users' requests are not sampled
+		return false, false, stateCopy.lastError
+	}
+	if stateCopy.state == state.Open {
+		if stateCopy.openExpiresAt.After(now) {
+			// still in the open state, not expired
+			return false, false, stateCopy.lastError
+		}
+
+		stateCopy = b.transitionToHalfOpenIfShould()
+	}
+
+	if stateCopy.state == state.HalfOpen {
+		sampleContext := SampleContext{
+			TimeInHalfOpen: b.opts.nowFactory.Get().Sub(stateCopy.halfOpenAt),
+			InFlight:       atomic.LoadInt64(&b.halfOpenInFlight),
+			Successes:      stateCopy.halfOpenSuccesses,
+			Priority:       attempt.Priority,
+			Key:            attempt.Key,
+		}
+		// the sampler may be stateful, such as sampling every nth request, so is asked exactly once
+		if !b.sampler().Sample(sampleContext) {
+			return false, false, stateCopy.lastError
+		}
+		return true, true, nil
+	}
+
+	if stateCopy.state == state.Closed && stateCopy.warmingUp {
+		if !b.admitWhileWarmingUp(stateCopy, now, attempt.Priority) {
+			return false, false, stateCopy.lastError
+		}
+	}
+	return true, false, nil
+}
+
 func (b *Breaker) copyCurrentState() (currentState mutableState, now time.Time) {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
-	currentState.state = b.state
-	currentState.openExpiresAt = b.openExpiresAt
-	currentState.lastError = b.lastError
+	currentState = b.mutableState
 	now = b.opts.nowFactory.Get()
 	return
 }
@@ -129,21 +336,27 @@ func (b *Breaker) transitionToHalfOpenIfShould() mutableState {
 	// are we still recorded as being in the open state and we should transition?
 	if b.state == state.Open && b.opts.nowFactory.Get().After(b.openExpiresAt) {
 		// perform the transition exactly once for this round
-		b.state = state.HalfOpen
+		b.setState(state.HalfOpen)
 		b.halfOpenAt = b.opts.nowFactory.Get()
 		b.halfOpenSuccesses = 0
+		event := b.newEvent()
 		afterUnlock = func() {
-			b.notifyStateChanged(state.HalfOpen)
+			b.notifyStateChanged(event)
 		}
 	}
 	return b.mutableState
 }
 
+// now is the current time according to the breaker's, possibly simulated, clock
+func (b *Breaker) now() time.Time {
+	return b.opts.nowFactory.Get()
+}
+
 // doNothing is a placeholder for a no-op
 func doNothing() {}
 
 // recordErrorAndTransitionToOpenIfShould will transition to the Open state if the breaker should trip
-func (b *Breaker) recordErrorAndTransitionToOpenIfShould(trippingError *tripping.Error) {
+func (b *Breaker) recordErrorAndTransitionToOpenIfShould(trippingError *tripping.Error, latency time.Duration) {
 	b.mu.Lock()
 	afterUnlock := doNothing
 	defer func() {
@@ -151,33 +364,91 @@ func (b *Breaker) recordErrorAndTransitionToOpenIfShould(trippingError *tripping
 		afterUnlock()
 	}()
 
-	if b.state == state.Closed {
-		// record the error
-		errorRateWithinLimits := !b.opts.TripDecider.ShouldTrip(trippingError)
+	// record the error
+	b.categoryFailures[trippingError.Category]++
+	b.opts.Recorder.OnFailure(trippingError, latency)
+	afterUnlock = b.noticeRegimeSwitch()
+	now := b.opts.nowFactory.Get()
+	var explanation tripping.Explanation
+	switch {
+	case b.state == state.Open:
+		// already transitioned state to open
+		return
+	case b.state == state.HalfOpen:
+		explanation = tripping.Explanation{Name: "HalfOpen", Tripped: true, Detail: "a sampled call failed"}
+	case b.isWarmingUp(now):
+		explanation = tripping.Explanation{Name: "WarmUp", Tripped: true, Detail: "a call failed while warming up"}
+	default:
+		errorRateWithinLimits := !b.opts.Recorder.ShouldTrip()
 		if errorRateWithinLimits {
+			// error rate not yet exceeded, no need to transition
 			return
 		}
+		// explain before transitioning, which resets the Recorder
+		explanation = tripping.Explain(b.opts.Recorder)
 	}
 
-	if b.state == state.Open {
-		// already transitioned state to open OR
-		// error rate not yet exceeded, no need to transition
-		return
+	afterUnlock = both(afterUnlock, b.transitionToOpen(trippingError.Err, explanation))
+}
+
+// recordSuccess tells the Recorder the call succeeded and returns the state the breaker is now in. The Recorder may
+// trip the breaker on more than failures, such as latency, so it is consulted while Closed and not warming up
+func (b *Breaker) recordSuccess(latency time.Duration) state.State {
+	b.mu.Lock()
+	afterUnlock := doNothing
+	defer func() {
+		b.mu.Unlock()
+		afterUnlock()
+	}()
+
+	b.opts.Recorder.OnSuccess(latency)
+	afterUnlock = b.noticeRegimeSwitch()
+	if b.state == state.Closed && !b.isWarmingUp(b.opts.nowFactory.Get()) && b.opts.Recorder.ShouldTrip() {
+		// explain before transitioning, which resets the Recorder
+		afterUnlock = both(afterUnlock, b.transitionToOpen(tripping.ErrRecorderTripped, tripping.Explain(b.opts.Recorder)))
 	}
+	return b.state
+}
+
+// transitionToOpen trips the breaker. Must hold the lock, call the returned func after unlocking
+func (b *Breaker) transitionToOpen(lastError error, explanation tripping.Explanation) (afterUnlock func()) {
+	b.lastError = lastError
+	b.tripExplanation = explanation
+	b.setState(state.Open)
+	b.openExpiresAt = b.opts.nowFactory.Get().Add(b.openDuration())
+	b.warmingUp = false
+	event := b.newEvent()
+	return func() {
+		b.notifyStateChanged(event)
+	}
+}
 
-	// transition to the Open State
-	b.lastError = trippingError.Err
-	b.state = state.Open
-	b.openExpiresAt = b.opts.nowFactory.Get().Add(b.opts.OpenDuration)
-	afterUnlock = func() {
-		b.notifyStateChanged(state.Open)
+// setState transitions to newState, resetting the Recorder. Must hold the lock
+func (b *Breaker) setState(newState state.State) {
+	b.state = newState
+	switch newState {
+	case state.Open:
+		b.opts.Recorder.Reset(tripping.Open)
+	case state.HalfOpen:
+		b.opts.Recorder.Reset(tripping.HalfOpen)
+	default:
+		b.opts.Recorder.Reset(tripping.Closed)
 	}
 }
 
-// notifyStateChanged will emit the newState if a OnStateChange listener was registered
-func (b *Breaker) notifyStateChanged(newState state.State) {
+// notifyStateChanged will emit the new state if a OnStateChange listener was registered and the event if an OnEvent
+// listener was registered
+func (b *Breaker) notifyStateChanged(event Event) {
 	if b.opts.OnStateChange != nil {
-		b.opts.OnStateChange <- newState
+		b.opts.OnStateChange <- event.State
+	}
+	b.notifyEvent(event)
+}
+
+// notifyEvent will emit the event if an OnEvent listener was registered
+func (b *Breaker) notifyEvent(event Event) {
+	if b.opts.OnEvent != nil {
+		b.opts.OnEvent <- event
 	}
 }
 
@@ -196,10 +467,52 @@ func (b *Breaker) recordSuccessAndTransitionToClosedIfShould() {
 		b.halfOpenSuccesses++
 		if b.halfOpenSuccesses >= b.opts.NumberOfSuccessesInHalfOpenToClose {
 			// perform the transition exactly once for this round
-			b.state = state.Closed
+			b.setState(state.Closed)
+			b.closedAt = b.opts.nowFactory.Get()
+			b.warmingUp = b.opts.WarmUpDuration > 0
+			event := b.newEvent()
 			afterUnlock = func() {
-				b.notifyStateChanged(state.Closed)
+				b.notifyStateChanged(event)
 			}
 		}
 	}
 }
+
+// CircuitState is the name of the state the breaker is currently in
+func (b *Breaker) CircuitState() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.state.String()
+}
+
+// RetryAfter is how long until the breaker will enter the HalfOpen state and begin sampling requests again.
+// Returns 0 if the breaker is not open or the open state has already expired.
+// With a HealthCheck, returns the HealthCheckInterval until the checks close the breaker
+func (b *Breaker) RetryAfter() time.Duration {
+	stateCopy, now := b.copyCurrentState()
+	if b.opts.HealthCheck != nil && stateCopy.state != state.Closed {
+		return b.opts.HealthCheckInterval
+	}
+	if stateCopy.state != state.Open || !stateCopy.openExpiresAt.After(now) {
+		return 0
+	}
+	return stateCopy.openExpiresAt.Sub(now)
+}
+
+// Decisions counts the calls the breaker admitted and rejected. In shadow mode, counts the calls it would have rejected
+func (b *Breaker) Decisions() shadow.Decisions {
+	return b.decisions.Decisions()
+}
+
+// TripExplanation describes why the breaker last tripped, see tripping.Explanation
+func (b *Breaker) TripExplanation() tripping.Explanation {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.tripExplanation
+}
+
+// Classify returns the tripping error the breaker records for err, or nil if err does not count against it, see
+// Opts.Classifier
+func (b *Breaker) Classify(err error) *tripping.Error {
+	return b.opts.Classifier.Classify(err)
+}
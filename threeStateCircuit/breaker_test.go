@@ -162,5 +162,117 @@ var _ = Describe("Breaker.Use", func() {
 				Expect(stateChange).Should(Receive(Equal(state.Closed)))
 			})
 		})
+		When("a sampled call fails", func() {
+			It("does not consult the TripDecider", func() {
+				decided := 0
+				breaker = New(Opts{
+					TripDecider: func(_ *tripping.Error) (shouldTrip bool) {
+						decided++
+						return true
+					},
+					HalfOpenSampler: samplerAlwaysSamples,
+				})
+				for i := 0; i < 2; i++ {
+					_ = breaker.Use(func() error {
+						return trippingError
+					})
+				}
+				Expect(breaker.CircuitState()).Should(Equal("Open"))
+				Expect(decided).Should(Equal(1))
+			})
+		})
+	})
+})
+
+var _ = Describe("Breaker.RetryAfter", func() {
+	var (
+		breaker *Breaker
+		now     time.Time
+	)
+	BeforeEach(func() {
+		now = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		breaker = New(Opts{
+			OpenDuration: 30 * time.Second,
+			nowFactory: func() time.Time {
+				return now
+			},
+		})
+	})
+	When("closed", func() {
+		It("does not need to wait", func() {
+			Expect(breaker.RetryAfter()).Should(Equal(time.Duration(0)))
+		})
+		It("describes the state", func() {
+			Expect(breaker.CircuitState()).Should(Equal("Closed"))
+		})
+	})
+	When("open", func() {
+		BeforeEach(func() {
+			_ = breaker.Use(func() error {
+				return trippingError
+			})
+			now = now.Add(10 * time.Second)
+		})
+		It("waits for the remainder of the open duration", func() {
+			Expect(breaker.RetryAfter()).Should(Equal(20 * time.Second))
+		})
+		It("describes the state", func() {
+			Expect(breaker.CircuitState()).Should(Equal("Open"))
+		})
+	})
+	When("half-open", func() {
+		BeforeEach(func() {
+			breaker.state = state.HalfOpen
+		})
+		It("does not need to wait", func() {
+			Expect(breaker.RetryAfter()).Should(Equal(time.Duration(0)))
+		})
+		It("describes the state", func() {
+			Expect(breaker.CircuitState()).Should(Equal("HalfOpen"))
+		})
+	})
+})
+
+var _ = Describe("Breaker.UseForced", func() {
+	var (
+		breaker *Breaker
+	)
+	BeforeEach(func() {
+		breaker = New(Opts{
+			Recorder:                           tripping.ConsecutiveFailures(1),
+			OpenDuration:                       time.Hour,
+			NumberOfSuccessesInHalfOpenToClose: 2,
+		})
+		_ = breaker.Use(func() error {
+			return trippingError
+		})
+	})
+	It("calls the callback while open", func() {
+		called := false
+		_ = breaker.UseForced(func() error {
+			called = true
+			return nil
+		})
+		Expect(called).Should(BeTrue())
+	})
+	It("counts a success towards closing", func() {
+		_ = breaker.UseForced(func() error {
+			return nil
+		})
+		Expect(breaker.CircuitState()).Should(Equal("HalfOpen"))
+		_ = breaker.UseForced(func() error {
+			return nil
+		})
+		Expect(breaker.CircuitState()).Should(Equal("Closed"))
+	})
+	It("re-opens on a failure", func() {
+		_ = breaker.UseForced(func() error {
+			return nil
+		})
+		err := breaker.UseForced(func() error {
+			return trippingError
+		})
+		Expect(err).Should(Equal(trippingError.Err))
+		Expect(breaker.CircuitState()).Should(Equal("Open"))
 	})
 })
@@ -1,6 +1,46 @@
 package threeStateCircuit
 
-import "time"
+import (
+	"github.com/wojnosystems/go-circuit-breaker/priority"
+	"time"
+)
+
+// SampleContext describes the request being considered while in the half-open state
+type SampleContext struct {
+	// TimeInHalfOpen is how long ago the breaker entered the half-open state
+	TimeInHalfOpen time.Duration
+
+	// InFlight is the number of sampled requests that have not yet completed
+	InFlight int64
+
+	// Successes is the number of sampled requests that succeeded since entering the half-open state
+	Successes uint64
+
+	// Priority of the request, from the Attempt
+	Priority priority.Priority
+
+	// Key of the request, from the Attempt
+	Key string
+}
+
+// Sampler tells the circuit breaker which requests to reject and which to attempt while in the half-open state
+type Sampler interface {
+	// Sample returns true if the request should be attempted
+	Sample(sampleContext SampleContext) (shouldSample bool)
+}
+
+// SamplerFunc adapts a plain func to the Sampler interface
+type SamplerFunc func(sampleContext SampleContext) (shouldSample bool)
+
+// Sample calls the func
+func (s SamplerFunc) Sample(sampleContext SampleContext) bool {
+	return s(sampleContext)
+}
+
+// ShouldSample calls the func with only the time in the half-open state known, as a Default priority request
+func (s SamplerFunc) ShouldSample(timeInHalfOpen time.Duration) bool {
+	return s(SampleContext{TimeInHalfOpen: timeInHalfOpen})
+}
 
 type ShouldSample func(timeInHalfOpen time.Duration) (shouldSample bool)
 
@@ -12,3 +52,16 @@ func (s ShouldSample) ShouldSample(timeInHalfOpen time.Duration) bool {
 	}
 	return true
 }
+
+// Sample adapts ShouldSample to the Sampler interface, it only considers the time in the half-open state
+func (s ShouldSample) Sample(sampleContext SampleContext) bool {
+	return s.ShouldSample(sampleContext.TimeInHalfOpen)
+}
+
+// sampler is the Sampler if set, otherwise the HalfOpenSampler, which attempts every request when nil
+func (b *Breaker) sampler() Sampler {
+	if b.opts.Sampler != nil {
+		return b.opts.Sampler
+	}
+	return b.opts.HalfOpenSampler
+}
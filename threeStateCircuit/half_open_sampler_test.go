@@ -37,3 +37,52 @@ func TestShouldSample_ShouldSample(t *testing.T) {
 		})
 	}
 }
+
+func TestShouldSample_Sample(t *testing.T) {
+	g := NewWithT(t)
+	var actual time.Duration
+	subject := ShouldSample(func(timeInHalfOpen time.Duration) bool {
+		actual = timeInHalfOpen
+		return true
+	})
+	g.Expect(subject.Sample(SampleContext{TimeInHalfOpen: time.Second, InFlight: 2})).Should(BeTrue())
+	g.Expect(actual).Should(Equal(time.Second))
+}
+
+func TestSamplerFunc_ShouldSample(t *testing.T) {
+	g := NewWithT(t)
+	var actual SampleContext
+	subject := SamplerFunc(func(sampleContext SampleContext) bool {
+		actual = sampleContext
+		return true
+	})
+	g.Expect(subject.ShouldSample(time.Second)).Should(BeTrue())
+	g.Expect(actual).Should(Equal(SampleContext{TimeInHalfOpen: time.Second}))
+}
+
+func TestBreaker_sampler(t *testing.T) {
+	alwaysSamples := SamplerFunc(func(_ SampleContext) bool {
+		return true
+	})
+	cases := map[string]struct {
+		opts     Opts
+		expected bool
+	}{
+		"neither set": {
+			expected: true,
+		},
+		"half-open sampler": {
+			opts: Opts{HalfOpenSampler: samplerNeverSamples},
+		},
+		"sampler takes precedence": {
+			opts:     Opts{HalfOpenSampler: samplerNeverSamples, Sampler: alwaysSamples},
+			expected: true,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(New(dt.opts).sampler().Sample(SampleContext{})).Should(Equal(dt.expected))
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package threeStateCircuit
+
+import (
+	"context"
+	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit/state"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"time"
+)
+
+const defaultHealthCheckInterval = time.Second
+
+// HealthCheck returns nil if the protected resource appears healthy. Return an error if it does not.
+// ctx is cancelled once HealthCheckTimeout passes or the breaker is closed, give up when it is done.
+type HealthCheck func(ctx context.Context) error
+
+// startProber runs the HealthCheck on a schedule in the background until Close is called
+func (b *Breaker) startProber() {
+	if b.opts.HealthCheckInterval <= 0 {
+		b.opts.HealthCheckInterval = defaultHealthCheckInterval
+	}
+	if b.opts.HealthCheckTimeout <= 0 {
+		b.opts.HealthCheckTimeout = b.opts.HealthCheckInterval
+	}
+	var ctx context.Context
+	ctx, b.stopProber = context.WithCancel(context.Background())
+	b.proberDone.Add(1)
+	go func() {
+		defer b.proberDone.Done()
+		ticker := time.NewTicker(b.opts.HealthCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if ctx.Err() != nil {
+					// closed while waiting for the tick
+					return
+				}
+				b.probeOnce(ctx)
+			}
+		}
+	}()
+}
+
+// probeOnce runs the HealthCheck if the breaker is not closed and records the result
+func (b *Breaker) probeOnce(ctx context.Context) {
+	b.mu.RLock()
+	currentState := b.state
+	b.mu.RUnlock()
+	if currentState == state.Closed {
+		return
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, b.opts.HealthCheckTimeout)
+	err := b.opts.HealthCheck(checkCtx)
+	cancel()
+	if ctx.Err() != nil {
+		// shutting down, the check was cancelled and says nothing about the resource
+		return
+	}
+	if err != nil {
+		b.recordProbeFailure(err)
+		return
+	}
+	b.recordProbeSuccess()
+}
+
+// recordProbeSuccess counts the successful check towards closing the breaker, entering HalfOpen first if it is Open
+func (b *Breaker) recordProbeSuccess() {
+	b.mu.Lock()
+	afterUnlock := doNothing
+	if b.state == state.Open {
+		b.setState(state.HalfOpen)
+		b.halfOpenAt = b.opts.nowFactory.Get()
+		b.halfOpenSuccesses = 0
+		event := b.newEvent()
+		afterUnlock = func() {
+			b.notifyStateChanged(event)
+		}
+	}
+	b.mu.Unlock()
+	afterUnlock()
+	b.recordSuccessAndTransitionToClosedIfShould()
+}
+
+// recordProbeFailure re-opens the breaker, restarting the count of successful checks
+func (b *Breaker) recordProbeFailure(err error) {
+	afterUnlock := doNothing
+	b.mu.Lock()
+	defer func() {
+		b.mu.Unlock()
+		afterUnlock()
+	}()
+	b.openExpiresAt = b.opts.nowFactory.Get().Add(b.openDuration())
+	if b.state == state.HalfOpen {
+		b.tripExplanation = tripping.Explanation{Name: "HealthCheck", Tripped: true, Detail: err.Error()}
+		b.setState(state.Open)
+		event := b.newEvent()
+		afterUnlock = func() {
+			b.notifyStateChanged(event)
+		}
+	}
+}
+
+// Close stops the HealthCheck prober, waiting for any check in progress to be cancelled.
+// Call this once the breaker is no longer needed. Does nothing if no HealthCheck was set.
+// The breaker may still be used after it is closed, but will no longer recover from the Open state on its own.
+func (b *Breaker) Close() {
+	if b.stopProber == nil {
+		return
+	}
+	b.stopProber()
+	b.proberDone.Wait()
+}
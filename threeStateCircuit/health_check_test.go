@@ -0,0 +1,144 @@
+package threeStateCircuit
+
+import (
+	"context"
+	"errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit/state"
+	"time"
+)
+
+var errUnhealthy = errors.New("unhealthy")
+
+var _ = Describe("Breaker.HealthCheck", func() {
+	var (
+		breaker     *Breaker
+		stateChange chan state.State
+		checkErr    error
+		checks      int
+	)
+	BeforeEach(func() {
+		stateChange = make(chan state.State, 10)
+		checkErr = nil
+		checks = 0
+		breaker = New(Opts{
+			OpenDuration:                       30 * time.Second,
+			OnStateChange:                      stateChange,
+			HalfOpenSampler:                    samplerAlwaysSamples,
+			NumberOfSuccessesInHalfOpenToClose: 2,
+			HealthCheck: func(ctx context.Context) error {
+				checks++
+				return checkErr
+			},
+			// long enough that the background prober never runs during the test
+			HealthCheckInterval: time.Hour,
+		})
+	})
+	AfterEach(func() {
+		breaker.Close()
+	})
+	When("closed", func() {
+		It("does not check", func() {
+			breaker.probeOnce(context.Background())
+			Expect(checks).Should(Equal(0))
+		})
+	})
+	When("open", func() {
+		BeforeEach(func() {
+			breaker.state = state.Open
+			breaker.openExpiresAt = time.Now().Add(-time.Second)
+			breaker.lastError = trippingError.Err
+		})
+		It("rejects users even when expired", func() {
+			err := breaker.Use(func() error {
+				Fail("should not be called")
+				return nil
+			})
+			Expect(err).Should(Equal(trippingError.Err))
+		})
+		It("waits for the next check", func() {
+			Expect(breaker.RetryAfter()).Should(Equal(time.Hour))
+		})
+		When("the check succeeds", func() {
+			It("transitions to half-open", func() {
+				breaker.probeOnce(context.Background())
+				Expect(stateChange).Should(Receive(Equal(state.HalfOpen)))
+				Expect(breaker.halfOpenSuccesses).Should(Equal(uint64(1)))
+			})
+			It("closes once enough checks succeed", func() {
+				breaker.probeOnce(context.Background())
+				breaker.probeOnce(context.Background())
+				Expect(stateChange).Should(Receive(Equal(state.HalfOpen)))
+				Expect(stateChange).Should(Receive(Equal(state.Closed)))
+			})
+		})
+		When("the check fails", func() {
+			BeforeEach(func() {
+				checkErr = errUnhealthy
+			})
+			It("stays open", func() {
+				breaker.probeOnce(context.Background())
+				Expect(stateChange).ShouldNot(Receive())
+				Expect(breaker.state).Should(Equal(state.Open))
+			})
+		})
+	})
+	When("half-open", func() {
+		BeforeEach(func() {
+			breaker.state = state.HalfOpen
+			breaker.halfOpenSuccesses = 1
+			breaker.lastError = trippingError.Err
+		})
+		It("rejects users", func() {
+			err := breaker.Use(func() error {
+				Fail("should not be called")
+				return nil
+			})
+			Expect(err).Should(Equal(trippingError.Err))
+		})
+		When("the check fails", func() {
+			BeforeEach(func() {
+				checkErr = errUnhealthy
+			})
+			It("transitions to open", func() {
+				breaker.probeOnce(context.Background())
+				Expect(stateChange).Should(Receive(Equal(state.Open)))
+			})
+			It("restarts the count of successes", func() {
+				breaker.probeOnce(context.Background())
+				checkErr = nil
+				breaker.probeOnce(context.Background())
+				Expect(breaker.state).Should(Equal(state.HalfOpen))
+			})
+		})
+	})
+})
+
+var _ = Describe("Breaker.Close", func() {
+	It("cancels the check in progress", func() {
+		started := make(chan struct{})
+		var checkCtx context.Context
+		breaker := New(Opts{
+			HealthCheck: func(ctx context.Context) error {
+				checkCtx = ctx
+				close(started)
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			HealthCheckInterval: time.Millisecond,
+			HealthCheckTimeout:  time.Hour,
+		})
+		breaker.mu.Lock()
+		breaker.state = state.Open
+		breaker.lastError = trippingError.Err
+		breaker.mu.Unlock()
+		Eventually(started).Should(BeClosed())
+		breaker.Close()
+		Expect(checkCtx.Err()).Should(Equal(context.Canceled))
+		Expect(breaker.CircuitState()).Should(Equal("Open"))
+	})
+	It("does nothing without a health check", func() {
+		New(Opts{}).Close()
+	})
+})
@@ -0,0 +1,37 @@
+package threeStateCircuit
+
+import (
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"time"
+)
+
+// noticeRegimeSwitch remembers the Recorder's active tripping.Regime, emitting an event if it switched since it was
+// last noticed. Must hold the lock, call the returned func after unlocking
+func (b *Breaker) noticeRegimeSwitch() (afterUnlock func()) {
+	regime, ok := tripping.FindActiveRegime(b.opts.Recorder)
+	if !ok || regime.Name == b.regime {
+		return doNothing
+	}
+	b.regime = regime.Name
+	event := b.newEvent()
+	return func() {
+		b.notifyEvent(event)
+	}
+}
+
+// openDuration is how long to stay open once tripped, the active tripping.Regime may override Opts.OpenDuration.
+// Must hold the lock
+func (b *Breaker) openDuration() time.Duration {
+	if regime, ok := tripping.FindActiveRegime(b.opts.Recorder); ok && regime.OpenDuration > 0 {
+		return regime.OpenDuration
+	}
+	return b.opts.OpenDuration
+}
+
+// both returns a func calling first, then second
+func both(first, second func()) func() {
+	return func() {
+		first()
+		second()
+	}
+}
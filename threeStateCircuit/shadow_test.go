@@ -0,0 +1,103 @@
+package threeStateCircuit
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/shadow"
+	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit/state"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"time"
+)
+
+var _ = Describe("Breaker shadow mode", func() {
+	var (
+		breaker     *Breaker
+		stateChange chan state.State
+	)
+	BeforeEach(func() {
+		stateChange = make(chan state.State, 10)
+		breaker = New(Opts{
+			OpenDuration:                       time.Hour,
+			OnStateChange:                      stateChange,
+			HalfOpenSampler:                    samplerNeverSamples,
+			NumberOfSuccessesInHalfOpenToClose: 1,
+			Shadow:                             true,
+		})
+		_ = breaker.Use(func() error {
+			return trippingError
+		})
+	})
+	It("transitions as usual", func() {
+		Expect(stateChange).Should(Receive(Equal(state.Open)))
+	})
+	It("never rejects", func() {
+		called := false
+		err := breaker.Use(func() error {
+			called = true
+			return nil
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(called).Should(BeTrue())
+		Expect(breaker.Decisions()).Should(Equal(shadow.Decisions{Admitted: 1, Rejected: 1}))
+	})
+	When("half-open", func() {
+		BeforeEach(func() {
+			breaker.state = state.HalfOpen
+		})
+		It("does not count successes of requests it would not have sampled", func() {
+			_ = breaker.Use(func() error {
+				return nil
+			})
+			Expect(breaker.CircuitState()).Should(Equal("HalfOpen"))
+		})
+	})
+})
+
+var _ = Describe("Breaker shadows", func() {
+	It("forwards the outcomes of attempted calls", func() {
+		tighter := New(Opts{
+			TripDecider: func(_ *tripping.Error) (shouldTrip bool) {
+				return true
+			},
+			OpenDuration: time.Hour,
+			Shadow:       true,
+		})
+		live := New(Opts{
+			TripDecider: func(_ *tripping.Error) (shouldTrip bool) {
+				return false
+			},
+			Shadows: []shadow.Breaker{tighter},
+		})
+		_ = live.Use(func() error {
+			return trippingError
+		})
+		Expect(live.CircuitState()).Should(Equal("Closed"))
+		Expect(tighter.CircuitState()).Should(Equal("Open"))
+	})
+	It("forwards the latencies of attempted calls", func() {
+		now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		slower := New(Opts{
+			Recorder: tripping.LatencyPercentile(tripping.LatencyPercentileOpts{
+				Percentile: 0.9,
+				Budget:     time.Second,
+				MinSamples: 3,
+			}),
+			OpenDuration: time.Hour,
+			Shadow:       true,
+		})
+		live := New(Opts{
+			Shadows: []shadow.Breaker{slower},
+			nowFactory: func() time.Time {
+				return now
+			},
+		})
+		for i := 0; i < 3; i++ {
+			_ = live.Use(func() error {
+				now = now.Add(2 * time.Second)
+				return nil
+			})
+		}
+		Expect(live.CircuitState()).Should(Equal("Closed"))
+		Expect(slower.CircuitState()).Should(Equal("Open"))
+	})
+})
@@ -0,0 +1,110 @@
+package threeStateCircuit
+
+import (
+	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit/state"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"time"
+)
+
+// Event is emitted on Opts.OnEvent each time the breaker transitions, when it finishes warming up and when it notices
+// the Recorder switched to another tripping.Regime
+type Event struct {
+	// State the breaker is in after the event
+	State state.State
+
+	// WarmingUp is true if the breaker is Closed and is still ramping traffic back up
+	WarmingUp bool
+
+	// WarmUpProgress is how far through the warm-up the breaker is, from 0 as it closes to 1 once warmed up.
+	// Always 1 if the breaker is not warming up
+	WarmUpProgress float64
+
+	// Regime is the name of the Recorder's active tripping.Regime, empty if the Recorder is not scheduled
+	Regime string
+
+	// At is when the event happened
+	At time.Time
+}
+
+// Snapshot is a copy of the breaker's state at a moment in time, use it for metrics and debugging
+type Snapshot struct {
+	State             state.State
+	LastError         error
+	OpenExpiresAt     time.Time
+	HalfOpenAt        time.Time
+	HalfOpenSuccesses uint64
+
+	// ClosedAt is when the breaker last transitioned from HalfOpen to Closed, zero if it never has
+	ClosedAt time.Time
+
+	// WarmingUp is true if the breaker is Closed and is still ramping traffic back up
+	WarmingUp bool
+
+	// WarmUpProgress is how far through the warm-up the breaker is, from 0 as it closes to 1 once warmed up.
+	// Always 1 if the breaker is not warming up
+	WarmUpProgress float64
+
+	// TripExplanation describes why the breaker last tripped
+	TripExplanation tripping.Explanation
+
+	// CategoryFailures counts every failure recorded since the breaker was created, by tripping.Category.
+	// Uncategorized failures are counted under the empty Category
+	CategoryFailures map[tripping.Category]uint64
+
+	// ErrorBudget of the Recorder, nil unless it has one, such as tripping.BurnRate
+	ErrorBudget *tripping.ErrorBudget
+
+	// LatencyPercentiles of the Recorder, nil unless it tracks them, such as tripping.LatencyPercentile
+	LatencyPercentiles []tripping.Percentile
+
+	// Regime is the name of the Recorder's active tripping.Regime when the breaker last noticed, empty if the Recorder
+	// is not scheduled
+	Regime string
+}
+
+// Snapshot copies the breaker's current state
+func (b *Breaker) Snapshot() Snapshot {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	now := b.opts.nowFactory.Get()
+	warmingUp := b.isWarmingUp(now)
+	snapshot := Snapshot{
+		State:             b.state,
+		LastError:         b.lastError,
+		OpenExpiresAt:     b.openExpiresAt,
+		HalfOpenAt:        b.halfOpenAt,
+		HalfOpenSuccesses: b.halfOpenSuccesses,
+		ClosedAt:          b.closedAt,
+		WarmingUp:         warmingUp,
+		WarmUpProgress:    b.mutableState.warmUpProgress(b.opts.WarmUpDuration, now),
+		TripExplanation:   b.tripExplanation,
+		CategoryFailures:  b.copyCategoryFailures(),
+		Regime:            b.regime,
+	}
+	if errorBudget, ok := tripping.FindErrorBudget(b.opts.Recorder); ok {
+		snapshot.ErrorBudget = &errorBudget
+	}
+	snapshot.LatencyPercentiles, _ = tripping.FindLatencyPercentiles(b.opts.Recorder)
+	return snapshot
+}
+
+// copyCategoryFailures so the snapshot does not change as failures are recorded. Must hold the lock
+func (b *Breaker) copyCategoryFailures() map[tripping.Category]uint64 {
+	categoryFailures := make(map[tripping.Category]uint64, len(b.categoryFailures))
+	for category, failures := range b.categoryFailures {
+		categoryFailures[category] = failures
+	}
+	return categoryFailures
+}
+
+// newEvent describes the breaker's current state as an Event. Must hold the lock
+func (b *Breaker) newEvent() Event {
+	now := b.opts.nowFactory.Get()
+	return Event{
+		State:          b.state,
+		WarmingUp:      b.isWarmingUp(now),
+		WarmUpProgress: b.mutableState.warmUpProgress(b.opts.WarmUpDuration, now),
+		Regime:         b.regime,
+		At:             now,
+	}
+}
@@ -5,11 +5,9 @@ import (
 	"github.com/wojnosystems/go-rate-limit/rateLimit"
 )
 
-// OptsWithTokenBucketTripDecider creates a new breaker backed by a token bucket limiter
+// OptsWithTokenBucketTripDecider creates a new breaker backed by a token bucket limiter.
+// The bucket is refilled each time the breaker closes
 func OptsWithTokenBucketTripDecider(breakerOpts Opts, tokenBucketOpts rateLimit.TokenBucketOpts) Opts {
-	tokenBucket := rateLimit.NewTokenBucket(tokenBucketOpts)
-	breakerOpts.TripDecider = func(trippingErr *tripping.Error) (shouldTrip bool) {
-		return !tokenBucket.Allowed(trippingErr.Cost)
-	}
+	breakerOpts.Recorder = tripping.NewTokenBucketRecorder(tokenBucketOpts)
 	return breakerOpts
 }
@@ -0,0 +1,158 @@
+package threeStateCircuit
+
+import (
+	"context"
+	"errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit/state"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"github.com/wojnosystems/go-rate-limit/rateLimit"
+	"time"
+)
+
+// These only check that each breaker wires the tripping package's features in, the features' behaviour is tested in
+// the tripping package
+var _ = Describe("Breaker with tripping features", func() {
+	var (
+		now time.Time
+	)
+	useAfter := func(breaker *Breaker, latency time.Duration, err error) {
+		_ = breaker.Use(func() error {
+			now = now.Add(latency)
+			return err
+		})
+	}
+	BeforeEach(func() {
+		now = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	})
+	It("classifies errors with the Classifier", func() {
+		breaker := New(Opts{
+			OpenDuration: time.Minute,
+			Classifier:   tripping.NewClassifier().Sentinel(context.DeadlineExceeded, tripping.Record, 1),
+		})
+		err := breaker.Use(func() error {
+			return context.DeadlineExceeded
+		})
+		Expect(err).Should(Equal(context.DeadlineExceeded))
+		Expect(breaker.CircuitState()).Should(Equal("Open"))
+	})
+	It("records categorized failures with the CategoryRecorders and counts them in snapshots", func() {
+		breaker := New(Opts{
+			Recorder:     tripping.ConsecutiveFailures(5),
+			OpenDuration: time.Minute,
+			CategoryRecorders: map[tripping.Category]tripping.Recorder{
+				"ConnectionRefused": tripping.ConsecutiveFailures(1),
+			},
+		})
+		useAfter(breaker, 0, tripping.New(errors.New("uncategorized")))
+		Expect(breaker.Snapshot().CategoryFailures).Should(Equal(map[tripping.Category]uint64{"": 1}))
+		useAfter(breaker, 0, tripping.NewWithCategory(errors.New("connection refused"), "ConnectionRefused"))
+		Expect(breaker.CircuitState()).Should(Equal("Open"))
+	})
+	It("weighs failures with the CostFunc using the breaker's clock", func() {
+		breaker := New(OptsWithTokenBucketTripDecider(Opts{
+			OpenDuration: time.Minute,
+			CostFunc:     tripping.LatencyCost(time.Second, 30),
+			nowFactory: func() time.Time {
+				return now
+			},
+		}, rateLimit.TokenBucketOpts{
+			Capacity:      10,
+			InitialTokens: 10,
+		}))
+		useAfter(breaker, 100*time.Millisecond, trippingError)
+		Expect(breaker.CircuitState()).Should(Equal("Closed"))
+		useAfter(breaker, 30*time.Second, trippingError)
+		Expect(breaker.CircuitState()).Should(Equal("Open"))
+	})
+	It("gives an EWMA Recorder the breaker's clock", func() {
+		breaker := New(Opts{
+			Recorder: tripping.EWMA(tripping.EWMAOpts{
+				HalfLife:             time.Minute,
+				FailureRateThreshold: 0.5,
+			}),
+			OpenDuration: time.Hour,
+			nowFactory: func() time.Time {
+				return now
+			},
+		})
+		useAfter(breaker, 0, nil)
+		useAfter(breaker, 10*time.Minute, trippingError)
+		Expect(breaker.CircuitState()).Should(Equal("Open"))
+	})
+	It("reports a BurnRate Recorder's error budget in snapshots using the breaker's clock", func() {
+		breaker := New(Opts{
+			Recorder: tripping.WithMinCalls(100, tripping.BurnRate(tripping.BurnRateOpts{
+				Objective:    0.9,
+				BudgetPeriod: time.Hour,
+			})),
+			nowFactory: func() time.Time {
+				return now
+			},
+		})
+		useAfter(breaker, 0, trippingError)
+		for i := 0; i < 9; i++ {
+			useAfter(breaker, 0, nil)
+		}
+		Expect(breaker.Snapshot().ErrorBudget.Remaining).Should(BeNumerically("~", 0, 0.0001))
+
+		now = now.Add(2 * time.Hour)
+		Expect(breaker.Snapshot().ErrorBudget.Remaining).Should(Equal(1.0))
+	})
+	It("gives a LatencyPercentile Recorder the latency of successes and reports it in snapshots", func() {
+		breaker := New(Opts{
+			Recorder: tripping.LatencyPercentile(tripping.LatencyPercentileOpts{
+				Percentile: 0.9,
+				Budget:     time.Second,
+				MinSamples: 2,
+				Reported:   []float64{0.5},
+			}),
+			OpenDuration: time.Hour,
+			nowFactory: func() time.Time {
+				return now
+			},
+		})
+		useAfter(breaker, 2*time.Second, nil)
+		percentiles := breaker.Snapshot().LatencyPercentiles
+		Expect(percentiles).Should(HaveLen(1))
+		Expect(percentiles[0].Latency).Should(BeNumerically("~", 2*time.Second, 20*time.Millisecond))
+		useAfter(breaker, 2*time.Second, nil)
+		Expect(breaker.CircuitState()).Should(Equal("Open"))
+		Expect(breaker.TripExplanation().Name).Should(Equal("LatencyPercentile"))
+	})
+	It("switches to a scheduled regime's Recorder and open duration", func() {
+		events := make(chan Event, 10)
+		// 2021-01-01 is a Friday
+		now = now.Add(8 * time.Hour)
+		breaker := New(Opts{
+			Recorder: tripping.Schedule(
+				tripping.Regime{Recorder: tripping.ConsecutiveFailures(1)},
+				tripping.Regime{
+					Name:         "BusinessHours",
+					Periods:      []tripping.Period{{Start: 9 * time.Hour, End: 17 * time.Hour}},
+					Recorder:     tripping.ConsecutiveFailures(2),
+					OpenDuration: time.Minute,
+				},
+			),
+			OpenDuration: time.Hour,
+			OnEvent:      events,
+			nowFactory: func() time.Time {
+				return now
+			},
+		})
+		Expect(breaker.Snapshot().Regime).Should(Equal("Otherwise"))
+
+		now = now.Add(2 * time.Hour)
+		useAfter(breaker, 0, trippingError)
+		Expect(breaker.CircuitState()).Should(Equal("Closed"))
+		Expect(events).Should(Receive(Equal(Event{
+			State:          state.Closed,
+			WarmUpProgress: 1,
+			Regime:         "BusinessHours",
+			At:             now,
+		})))
+		useAfter(breaker, 0, trippingError)
+		Expect(breaker.Snapshot().OpenExpiresAt).Should(Equal(now.Add(time.Minute)))
+	})
+})
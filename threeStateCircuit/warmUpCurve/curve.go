@@ -0,0 +1,11 @@
+//go:generate go-enum --file=$GOFILE -noprefix
+
+package warmUpCurve
+
+// Curve is how quickly the chance of admitting a request grows while the breaker warms up
+/* ENUM(
+Linear,
+Exponential
+)
+*/
+type Curve uint8
@@ -0,0 +1,82 @@
+package threeStateCircuit
+
+import (
+	"github.com/wojnosystems/go-circuit-breaker/priority"
+	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit/state"
+	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit/warmUpCurve"
+	"math"
+	"math/rand"
+	"time"
+)
+
+const defaultWarmUpStartChance = 0.1
+
+// startWarmUps prepares the breaker to warm up each time it closes
+func (b *Breaker) startWarmUps() {
+	if b.opts.WarmUpStartChance <= 0 {
+		b.opts.WarmUpStartChance = defaultWarmUpStartChance
+	}
+	if b.opts.WarmUpStartChance > 1 {
+		b.opts.WarmUpStartChance = 1
+	}
+	randomSource := b.opts.WarmUpRandomSource
+	if randomSource == nil {
+		randomSource = rand.NewSource(time.Now().UnixNano())
+	}
+	b.random = rand.New(randomSource)
+}
+
+// admitWhileWarmingUp returns true if the request should be attempted. Finishes the warm-up once it has run its course
+func (b *Breaker) admitWhileWarmingUp(stateCopy mutableState, now time.Time, p priority.Priority) bool {
+	progress := stateCopy.warmUpProgress(b.opts.WarmUpDuration, now)
+	if progress >= 1 {
+		b.finishWarmUp()
+		return true
+	}
+	chance := warmUpChance(b.opts.WarmUpCurve, b.opts.WarmUpStartChance, progress)
+	b.randomMu.Lock()
+	randomValue := b.random.Float64()
+	b.randomMu.Unlock()
+	return randomValue < priority.Chance(p, chance)
+}
+
+// finishWarmUp stops warming up, admitting all requests again
+func (b *Breaker) finishWarmUp() {
+	afterUnlock := doNothing
+	b.mu.Lock()
+	defer func() {
+		b.mu.Unlock()
+		afterUnlock()
+	}()
+	// perform the transition exactly once for this round
+	if b.state == state.Closed && b.warmingUp && !b.isWarmingUp(b.opts.nowFactory.Get()) {
+		b.warmingUp = false
+		event := b.newEvent()
+		afterUnlock = func() {
+			b.notifyEvent(event)
+		}
+	}
+}
+
+// isWarmingUp is true if the breaker closed less than WarmUpDuration ago. Must hold the lock
+func (b *Breaker) isWarmingUp(now time.Time) bool {
+	return b.warmingUp && now.Before(b.closedAt.Add(b.opts.WarmUpDuration))
+}
+
+// warmUpProgress is how far through the warm-up the breaker is, from 0 as it closes to 1 when warmed up
+func (m mutableState) warmUpProgress(warmUpDuration time.Duration, now time.Time) float64 {
+	if !m.warmingUp || warmUpDuration <= 0 {
+		return 1
+	}
+	progress := float64(now.Sub(m.closedAt)) / float64(warmUpDuration)
+	return math.Min(math.Max(progress, 0), 1)
+}
+
+// warmUpChance is the chance of attempting a request when the warm-up has progressed this far
+func warmUpChance(curve warmUpCurve.Curve, startChance float64, progress float64) float64 {
+	if curve == warmUpCurve.Exponential {
+		// grows by the same factor over each equal slice of the warm-up, doubling traffic as often as it can
+		return startChance * math.Pow(1/startChance, progress)
+	}
+	return startChance + (1-startChance)*progress
+}
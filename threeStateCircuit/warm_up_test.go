@@ -0,0 +1,169 @@
+package threeStateCircuit
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit/state"
+	"github.com/wojnosystems/go-circuit-breaker/threeStateCircuit/warmUpCurve"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"math"
+	"testing"
+	"time"
+)
+
+type randSrcAlwaysHalf struct {
+}
+
+func (r *randSrcAlwaysHalf) Int63() int64 {
+	return math.MaxInt64 / 2
+}
+
+func (r *randSrcAlwaysHalf) Seed(_ int64) {
+}
+
+var _ = Describe("Breaker warm-up", func() {
+	var (
+		breaker *Breaker
+		events  chan Event
+		now     time.Time
+	)
+	BeforeEach(func() {
+		now = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		events = make(chan Event, 10)
+		breaker = New(Opts{
+			TripDecider: func(_ *tripping.Error) (shouldTrip bool) {
+				return false
+			},
+			OpenDuration:                       time.Minute,
+			OnEvent:                            events,
+			HalfOpenSampler:                    samplerAlwaysSamples,
+			NumberOfSuccessesInHalfOpenToClose: 1,
+			WarmUpDuration:                     10 * time.Second,
+			WarmUpStartChance:                  0.2,
+			WarmUpRandomSource:                 &randSrcAlwaysHalf{},
+			nowFactory: func() time.Time {
+				return now
+			},
+		})
+		breaker.state = state.HalfOpen
+		breaker.halfOpenAt = now
+		breaker.lastError = trippingError.Err
+		_ = breaker.Use(func() error {
+			return nil
+		})
+	})
+	It("emits the start of the warm-up", func() {
+		Expect(events).Should(Receive(Equal(Event{
+			State:          state.Closed,
+			WarmingUp:      true,
+			WarmUpProgress: 0,
+			At:             now,
+		})))
+	})
+	When("just closed", func() {
+		It("rejects requests over the starting chance", func() {
+			err := breaker.Use(func() error {
+				Fail("should not be called")
+				return nil
+			})
+			Expect(err).Should(Equal(trippingError.Err))
+		})
+	})
+	When("part way through", func() {
+		BeforeEach(func() {
+			now = now.Add(5 * time.Second)
+		})
+		It("admits more requests", func() {
+			called := false
+			_ = breaker.Use(func() error {
+				called = true
+				return nil
+			})
+			Expect(called).Should(BeTrue())
+		})
+		It("reports the progress", func() {
+			snapshot := breaker.Snapshot()
+			Expect(snapshot.WarmingUp).Should(BeTrue())
+			Expect(snapshot.WarmUpProgress).Should(BeNumerically("~", 0.5, 0.0001))
+		})
+		It("re-opens on the first tripping error", func() {
+			<-events
+			_ = breaker.Use(func() error {
+				return trippingError
+			})
+			Expect(events).Should(Receive(WithTransform(func(e Event) state.State {
+				return e.State
+			}, Equal(state.Open))))
+		})
+	})
+	When("warmed up", func() {
+		BeforeEach(func() {
+			now = now.Add(10 * time.Second)
+			<-events
+			_ = breaker.Use(func() error {
+				return nil
+			})
+		})
+		It("emits the end of the warm-up", func() {
+			Expect(events).Should(Receive(Equal(Event{
+				State:          state.Closed,
+				WarmingUp:      false,
+				WarmUpProgress: 1,
+				At:             now,
+			})))
+		})
+		It("consults the TripDecider again", func() {
+			_ = breaker.Use(func() error {
+				return trippingError
+			})
+			Expect(breaker.Snapshot().State).Should(Equal(state.Closed))
+		})
+	})
+})
+
+func TestWarmUpChance(t *testing.T) {
+	cases := map[string]struct {
+		curve       warmUpCurve.Curve
+		startChance float64
+		progress    float64
+		expected    float64
+	}{
+		"linear at the start": {
+			curve:       warmUpCurve.Linear,
+			startChance: 0.2,
+			progress:    0,
+			expected:    0.2,
+		},
+		"linear half way": {
+			curve:       warmUpCurve.Linear,
+			startChance: 0.2,
+			progress:    0.5,
+			expected:    0.6,
+		},
+		"exponential at the start": {
+			curve:       warmUpCurve.Exponential,
+			startChance: 0.25,
+			progress:    0,
+			expected:    0.25,
+		},
+		"exponential half way": {
+			curve:       warmUpCurve.Exponential,
+			startChance: 0.25,
+			progress:    0.5,
+			expected:    0.5,
+		},
+		"exponential at the end": {
+			curve:       warmUpCurve.Exponential,
+			startChance: 0.25,
+			progress:    1,
+			expected:    1,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			actual := warmUpChance(dt.curve, dt.startChance, dt.progress)
+			g.Expect(actual).Should(BeNumerically("~", dt.expected, 0.0001))
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package tripping
+
+import (
+	"fmt"
+	"github.com/wojnosystems/go-circuit-breaker/slidingWindow"
+	"time"
+)
+
+const (
+	defaultBurnRateBuckets    = 60
+	defaultErrorBudgetPeriod  = 30 * 24 * time.Hour
+	defaultErrorBudgetBuckets = 30
+	defaultBurnRateObjective  = 0.999
+)
+
+// BurnRateWindows pairs a short and a long window. Both must burn the error budget faster than Threshold to trip
+type BurnRateWindows struct {
+	// Short window, such as 5 minutes, stops the breaker tripping once the problem has passed
+	Short time.Duration
+
+	// Long window, such as 1 hour, stops the breaker tripping on a brief spike
+	Long time.Duration
+
+	// Threshold is how many times faster than the objective allows the budget must be burnt, such as 14.4
+	Threshold float64
+}
+
+type BurnRateOpts struct {
+	// Objective is the fraction of calls that should succeed, such as 0.999. Defaults to 0.999
+	Objective float64
+
+	// Windows to compare, the breaker trips if both windows of any pair burn faster than their Threshold
+	Windows []BurnRateWindows
+
+	// Buckets each window is divided into, see slidingWindow.New. Defaults to 60
+	Buckets int
+
+	// BudgetPeriod is the period the error budget covers, such as 30 days. It is only used to report the remaining
+	// error budget. Defaults to 30 days, divided into 30 buckets
+	BudgetPeriod time.Duration
+}
+
+// ErrorBudget is how much of an SLO's error budget has been spent
+type ErrorBudget struct {
+	// Objective is the fraction of calls that should succeed
+	Objective float64
+
+	// Period the budget covers
+	Period time.Duration
+
+	// Calls and Failures made in the Period
+	Calls    float64
+	Failures float64
+
+	// Remaining is the fraction of the budget left, 1 if no failures were recorded and negative once overspent
+	Remaining float64
+}
+
+// ErrorBudgeter is implemented by Recorders that track an error budget, breakers report it in their snapshots
+type ErrorBudgeter interface {
+	ErrorBudget() ErrorBudget
+}
+
+// FindErrorBudget returns the error budget of the first ErrorBudgeter in recorder's tree, see Walk
+func FindErrorBudget(recorder Recorder) (budget ErrorBudget, ok bool) {
+	Walk(recorder, func(recorder Recorder) {
+		if budgeter, isBudgeter := recorder.(ErrorBudgeter); isBudgeter && !ok {
+			budget, ok = budgeter.ErrorBudget(), true
+		}
+	})
+	return
+}
+
+// BurnRate trips like an SLO burn rate alert: when the error budget of a success Objective is being spent too fast
+// over both a short and a long window. The burn rate is the failure rate divided by the failure rate the Objective
+// allows, so 14.4 over an hour spends 2% of a 30 day budget.
+//
+// Example, the pairs recommended for paging on a 99.9% objective:
+//
+//	tripping.BurnRate(tripping.BurnRateOpts{
+//		Objective: 0.999,
+//		Windows: []tripping.BurnRateWindows{
+//			{Short: 5 * time.Minute, Long: time.Hour, Threshold: 14.4},
+//			{Short: 30 * time.Minute, Long: 6 * time.Hour, Threshold: 6},
+//		},
+//	})
+//
+// A single failure is an enormous burn rate at low traffic, so combine this with WithMinCalls.
+// The windows are cleared each time the breaker closes, the error budget is not.
+func BurnRate(opts BurnRateOpts) Recorder {
+	if opts.Objective <= 0 || opts.Objective >= 1 {
+		opts.Objective = defaultBurnRateObjective
+	}
+	if opts.Buckets <= 0 {
+		opts.Buckets = defaultBurnRateBuckets
+	}
+	if opts.BudgetPeriod <= 0 {
+		opts.BudgetPeriod = defaultErrorBudgetPeriod
+	}
+	windows := make([]burnRateWindowPair, len(opts.Windows))
+	for i, pair := range opts.Windows {
+		windows[i] = burnRateWindowPair{
+			BurnRateWindows: pair,
+			short:           newCallWindow(pair.Short, opts.Buckets),
+			long:            newCallWindow(pair.Long, opts.Buckets),
+		}
+	}
+	return &burnRateRecorder{
+		opts:    opts,
+		windows: windows,
+		budget:  newCallWindow(opts.BudgetPeriod, defaultErrorBudgetBuckets),
+	}
+}
+
+// callWindow counts calls and failures over a sliding window
+type callWindow struct {
+	calls    *slidingWindow.Counter
+	failures *slidingWindow.Counter
+}
+
+func newCallWindow(window time.Duration, buckets int) callWindow {
+	return callWindow{
+		calls:    slidingWindow.New(window, buckets),
+		failures: slidingWindow.New(window, buckets),
+	}
+}
+
+func (c callWindow) add(now time.Time, failed bool) {
+	c.calls.Add(now, 1)
+	if failed {
+		c.failures.Add(now, 1)
+	}
+}
+
+// failureRate in the window ending at now, 0 if there were no calls
+func (c callWindow) failureRate(now time.Time) float64 {
+	calls := c.calls.Sum(now)
+	if calls == 0 {
+		return 0
+	}
+	return c.failures.Sum(now) / calls
+}
+
+func (c callWindow) reset() {
+	c.calls.Reset()
+	c.failures.Reset()
+}
+
+// burnRateWindowPair tracks the calls in a pair of windows
+type burnRateWindowPair struct {
+	BurnRateWindows
+	short callWindow
+	long  callWindow
+}
+
+// burnRateRecorder is the Recorder created by BurnRate
+type burnRateRecorder struct {
+	clock
+	opts    BurnRateOpts
+	windows []burnRateWindowPair
+	budget  callWindow
+}
+
+func (b *burnRateRecorder) OnSuccess(_ time.Duration) {
+	b.record(false)
+}
+
+func (b *burnRateRecorder) OnFailure(_ *Error, _ time.Duration) {
+	b.record(true)
+}
+
+func (b *burnRateRecorder) record(failed bool) {
+	now := b.Now()
+	for _, pair := range b.windows {
+		pair.short.add(now, failed)
+		pair.long.add(now, failed)
+	}
+	b.budget.add(now, failed)
+}
+
+// burnRate is how many times faster than the objective allows the budget is being spent in the window
+func (b *burnRateRecorder) burnRate(window callWindow, now time.Time) float64 {
+	return window.failureRate(now) / (1 - b.opts.Objective)
+}
+
+// pairTripped is true if both windows of the pair are burning faster than its threshold
+func (b *burnRateRecorder) pairTripped(pair burnRateWindowPair, now time.Time) bool {
+	return b.burnRate(pair.short, now) > pair.Threshold && b.burnRate(pair.long, now) > pair.Threshold
+}
+
+func (b *burnRateRecorder) ShouldTrip() bool {
+	now := b.Now()
+	for _, pair := range b.windows {
+		if b.pairTripped(pair, now) {
+			return true
+		}
+	}
+	return false
+}
+
+// Reset clears the windows when the breaker closes, so the failures that tripped it do not trip it again
+func (b *burnRateRecorder) Reset(state State) {
+	if state != Closed {
+		return
+	}
+	for _, pair := range b.windows {
+		pair.short.reset()
+		pair.long.reset()
+	}
+}
+
+func (b *burnRateRecorder) ErrorBudget() ErrorBudget {
+	now := b.Now()
+	budget := ErrorBudget{
+		Objective: b.opts.Objective,
+		Period:    b.opts.BudgetPeriod,
+		Calls:     b.budget.calls.Sum(now),
+		Failures:  b.budget.failures.Sum(now),
+		Remaining: 1,
+	}
+	if budget.Calls > 0 {
+		budget.Remaining = 1 - budget.Failures/(budget.Calls*(1-b.opts.Objective))
+	}
+	return budget
+}
+
+func (b *burnRateRecorder) Explain() Explanation {
+	now := b.Now()
+	children := make([]Explanation, len(b.windows))
+	for i, pair := range b.windows {
+		children[i] = Explanation{
+			Name:    fmt.Sprintf("%s/%s", pair.Short, pair.Long),
+			Tripped: b.pairTripped(pair, now),
+			Detail: fmt.Sprintf("burning %.1fx/%.1fx, threshold %.1fx",
+				b.burnRate(pair.short, now), b.burnRate(pair.long, now), pair.Threshold),
+		}
+	}
+	return Explanation{
+		Name:     "BurnRate",
+		Tripped:  b.ShouldTrip(),
+		Detail:   fmt.Sprintf("%.0f%% of error budget left", b.ErrorBudget().Remaining*100),
+		Children: children,
+	}
+}
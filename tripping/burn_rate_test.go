@@ -0,0 +1,149 @@
+package tripping_test
+
+import (
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"testing"
+	"time"
+)
+
+// newBurnRate allows 10% of calls to fail, tripping when over 20% fail in both the last minute and the last 10 minutes
+func newBurnRate() tripping.Recorder {
+	return tripping.BurnRate(tripping.BurnRateOpts{
+		Objective: 0.9,
+		Windows: []tripping.BurnRateWindows{
+			{Short: time.Minute, Long: 10 * time.Minute, Threshold: 2},
+		},
+		BudgetPeriod: time.Hour,
+	})
+}
+
+func TestBurnRate(t *testing.T) {
+	cases := map[string]struct {
+		record   func(recorder tripping.Recorder, advance func(time.Duration))
+		expected bool
+	}{
+		"no calls": {
+			record: func(recorder tripping.Recorder, advance func(time.Duration)) {},
+		},
+		"both windows burning": {
+			record: func(recorder tripping.Recorder, advance func(time.Duration)) {
+				succeedTimes(recorder, 7)
+				failTimes(recorder, wrappedError, 3)
+			},
+			expected: true,
+		},
+		"under the threshold": {
+			record: func(recorder tripping.Recorder, advance func(time.Duration)) {
+				succeedTimes(recorder, 9)
+				failTimes(recorder, wrappedError, 1)
+			},
+		},
+		"brief spike": {
+			record: func(recorder tripping.Recorder, advance func(time.Duration)) {
+				succeedTimes(recorder, 20)
+				advance(5 * time.Minute)
+				failTimes(recorder, wrappedError, 3)
+			},
+		},
+		"problem has passed": {
+			record: func(recorder tripping.Recorder, advance func(time.Duration)) {
+				failTimes(recorder, wrappedError, 3)
+				advance(5 * time.Minute)
+				succeedTimes(recorder, 1)
+			},
+		},
+		"cleared when closed": {
+			record: func(recorder tripping.Recorder, advance func(time.Duration)) {
+				failTimes(recorder, wrappedError, 3)
+				recorder.Reset(tripping.Closed)
+				succeedTimes(recorder, 1)
+			},
+		},
+		"not cleared when opened": {
+			record: func(recorder tripping.Recorder, advance func(time.Duration)) {
+				failTimes(recorder, wrappedError, 3)
+				recorder.Reset(tripping.Open)
+			},
+			expected: true,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+			subject := newBurnRate()
+			tripping.SetClock(subject, func() time.Time {
+				return now
+			})
+			dt.record(subject, func(d time.Duration) {
+				now = now.Add(d)
+			})
+			g.Expect(subject.ShouldTrip()).Should(Equal(dt.expected))
+		})
+	}
+}
+
+func TestFindErrorBudget(t *testing.T) {
+	cases := map[string]struct {
+		record            func(recorder tripping.Recorder)
+		expectedRemaining float64
+	}{
+		"untouched": {
+			record:            func(recorder tripping.Recorder) {},
+			expectedRemaining: 1,
+		},
+		"half spent": {
+			record: func(recorder tripping.Recorder) {
+				succeedTimes(recorder, 19)
+				failTimes(recorder, wrappedError, 1)
+			},
+			expectedRemaining: 0.5,
+		},
+		"survives closing": {
+			record: func(recorder tripping.Recorder) {
+				succeedTimes(recorder, 19)
+				failTimes(recorder, wrappedError, 1)
+				recorder.Reset(tripping.Closed)
+			},
+			expectedRemaining: 0.5,
+		},
+		"overspent": {
+			record: func(recorder tripping.Recorder) {
+				succeedTimes(recorder, 5)
+				failTimes(recorder, wrappedError, 5)
+			},
+			expectedRemaining: -4,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			subject := tripping.WithMinCalls(10, newBurnRate())
+			dt.record(subject)
+			budget, ok := tripping.FindErrorBudget(subject)
+			g.Expect(ok).Should(BeTrue())
+			g.Expect(budget.Objective).Should(Equal(0.9))
+			g.Expect(budget.Period).Should(Equal(time.Hour))
+			g.Expect(budget.Remaining).Should(BeNumerically("~", dt.expectedRemaining, 0.0001))
+		})
+	}
+}
+
+func TestFindErrorBudget_NoBudget(t *testing.T) {
+	g := NewWithT(t)
+	_, ok := tripping.FindErrorBudget(tripping.Either(trips, neverTrips))
+	g.Expect(ok).Should(BeFalse())
+}
+
+func TestWalk(t *testing.T) {
+	g := NewWithT(t)
+	consecutive := tripping.ConsecutiveFailures(1)
+	var visited []tripping.Recorder
+	tripping.Walk(tripping.Either(trips, tripping.Negate(consecutive)), func(recorder tripping.Recorder) {
+		visited = append(visited, recorder)
+	})
+	g.Expect(visited).Should(HaveLen(4))
+	g.Expect(visited[1]).Should(BeIdenticalTo(trips))
+	g.Expect(visited[3]).Should(BeIdenticalTo(consecutive))
+}
@@ -0,0 +1,104 @@
+package tripping
+
+import (
+	"sort"
+	"time"
+)
+
+// Category of a tripping Error, such as "Timeout". The empty Category is uncategorized
+type Category string
+
+// ByCategory gives each category of failure its own Recorder, such as to trip on 3 connection refusals in a row or
+// on half of calls failing with a server error. Failures in categories without a Recorder, including uncategorized
+// ones, are told to otherwise.
+//
+// Every call is told to each Recorder once: as a failure to the Recorder for the failure's category and as a success
+// to all the others, so rates are of all calls made. ByCategory trips as soon as any of its Recorders trips.
+func ByCategory(otherwise Recorder, byCategory map[Category]Recorder) Recorder {
+	categories := make([]Category, 0, len(byCategory))
+	for category := range byCategory {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i] < categories[j]
+	})
+	return &categoryRecorder{
+		otherwise:  otherwise,
+		byCategory: byCategory,
+		categories: categories,
+	}
+}
+
+// categoryRecorder is the Recorder created by ByCategory
+type categoryRecorder struct {
+	otherwise  Recorder
+	byCategory map[Category]Recorder
+
+	// categories are the keys of byCategory in order, so explanations are stable
+	categories []Category
+}
+
+func (c *categoryRecorder) OnSuccess(latency time.Duration) {
+	c.recorders(func(recorder Recorder) {
+		recorder.OnSuccess(latency)
+	})
+}
+
+func (c *categoryRecorder) OnFailure(trippingErr *Error, latency time.Duration) {
+	if _, categorized := c.byCategory[trippingErr.Category]; categorized {
+		c.otherwise.OnSuccess(latency)
+	} else {
+		c.otherwise.OnFailure(trippingErr, latency)
+	}
+	for _, category := range c.categories {
+		if category == trippingErr.Category {
+			c.byCategory[category].OnFailure(trippingErr, latency)
+		} else {
+			c.byCategory[category].OnSuccess(latency)
+		}
+	}
+}
+
+func (c *categoryRecorder) ShouldTrip() bool {
+	tripped := false
+	c.recorders(func(recorder Recorder) {
+		tripped = tripped || recorder.ShouldTrip()
+	})
+	return tripped
+}
+
+func (c *categoryRecorder) Reset(state State) {
+	c.recorders(func(recorder Recorder) {
+		recorder.Reset(state)
+	})
+}
+
+func (c *categoryRecorder) Recorders() []Recorder {
+	var recorders []Recorder
+	c.recorders(func(recorder Recorder) {
+		recorders = append(recorders, recorder)
+	})
+	return recorders
+}
+
+// recorders calls each with otherwise, then the recorder for each category in order
+func (c *categoryRecorder) recorders(each func(recorder Recorder)) {
+	each(c.otherwise)
+	for _, category := range c.categories {
+		each(c.byCategory[category])
+	}
+}
+
+func (c *categoryRecorder) Explain() Explanation {
+	otherwise := Explain(c.otherwise)
+	children := []Explanation{{Name: "Otherwise", Tripped: otherwise.Tripped, Children: []Explanation{otherwise}}}
+	for _, category := range c.categories {
+		explanation := Explain(c.byCategory[category])
+		children = append(children, Explanation{
+			Name:     string(category),
+			Tripped:  explanation.Tripped,
+			Children: []Explanation{explanation},
+		})
+	}
+	return Explanation{Name: "ByCategory", Tripped: c.ShouldTrip(), Children: children}
+}
@@ -0,0 +1,99 @@
+package tripping_test
+
+import (
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"testing"
+	"time"
+)
+
+const (
+	refused  tripping.Category = "ConnectionRefused"
+	timedOut tripping.Category = "Timeout"
+)
+
+func failInCategory(recorder tripping.Recorder, category tripping.Category, times int) {
+	for i := 0; i < times; i++ {
+		recorder.OnFailure(tripping.NewWithCategory(wrappedError, category), time.Second)
+	}
+}
+
+func TestByCategory(t *testing.T) {
+	cases := map[string]struct {
+		record   func(recorder tripping.Recorder)
+		expected bool
+	}{
+		"below every threshold": {
+			record: func(recorder tripping.Recorder) {
+				failInCategory(recorder, refused, 2)
+				failInCategory(recorder, "", 1)
+			},
+		},
+		"category threshold": {
+			record: func(recorder tripping.Recorder) {
+				failInCategory(recorder, refused, 3)
+			},
+			expected: true,
+		},
+		"other categories interrupt a streak": {
+			record: func(recorder tripping.Recorder) {
+				failInCategory(recorder, refused, 2)
+				failInCategory(recorder, timedOut, 1)
+				failInCategory(recorder, refused, 1)
+			},
+		},
+		"uncategorized failures go to otherwise": {
+			record: func(recorder tripping.Recorder) {
+				failInCategory(recorder, "", 2)
+			},
+			expected: true,
+		},
+		"unconfigured categories go to otherwise": {
+			record: func(recorder tripping.Recorder) {
+				failInCategory(recorder, "Other", 2)
+			},
+			expected: true,
+		},
+		"categorized failures are successes to otherwise": {
+			record: func(recorder tripping.Recorder) {
+				failInCategory(recorder, "", 1)
+				failInCategory(recorder, timedOut, 1)
+				failInCategory(recorder, "", 1)
+			},
+		},
+		"reset": {
+			record: func(recorder tripping.Recorder) {
+				failInCategory(recorder, refused, 3)
+				recorder.Reset(tripping.Closed)
+			},
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			subject := tripping.ByCategory(tripping.ConsecutiveFailures(2), map[tripping.Category]tripping.Recorder{
+				refused:  tripping.ConsecutiveFailures(3),
+				timedOut: tripping.ConsecutiveFailures(5),
+			})
+			dt.record(subject)
+			g.Expect(subject.ShouldTrip()).Should(Equal(dt.expected))
+		})
+	}
+}
+
+func TestByCategory_Explain(t *testing.T) {
+	g := NewWithT(t)
+	subject := tripping.ByCategory(tripping.ConsecutiveFailures(2), map[tripping.Category]tripping.Recorder{
+		timedOut: tripping.ConsecutiveFailures(5),
+		refused:  tripping.ConsecutiveFailures(3),
+	})
+	failInCategory(subject, refused, 3)
+
+	g.Expect(tripping.Explain(subject).String()).Should(Equal(`ByCategory: tripped
+  Otherwise: ok
+    ConsecutiveFailures: ok (0 of 2 failures in a row)
+  ConnectionRefused: tripped
+    ConsecutiveFailures: tripped (3 of 3 failures in a row)
+  Timeout: ok
+    ConsecutiveFailures: ok (0 of 5 failures in a row)`))
+}
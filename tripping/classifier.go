@@ -0,0 +1,108 @@
+package tripping
+
+import (
+	"errors"
+	"reflect"
+)
+
+// Classifier decides which errors count against a breaker, so callers can return plain errors rather than wrapping
+// each one with New. Register sentinel errors with Sentinel and error types with Type, each with a Policy and cost.
+// Registrations are checked in the order they were made and the first match wins. Errors that match nothing use the
+// policy given to Otherwise, which defaults to Ignore.
+//
+// Errors that already are, or wrap, a tripping error are always recorded as they are, the caller chose to trip.
+//
+// Register everything before giving the Classifier to a breaker, it is not safe to register while it is in use.
+//
+// Example:
+//
+//	classifier := tripping.NewClassifier().
+//		Sentinel(context.Canceled, tripping.Ignore, 0).
+//		Sentinel(context.DeadlineExceeded, tripping.Record, 2).
+//		Type(&net.OpError{}, tripping.Record, 1)
+type Classifier struct {
+	classifications []classification
+	otherwise       classification
+}
+
+// classification is a registered rule, matches reports if it applies to an error
+type classification struct {
+	matches func(err error) bool
+	policy  Policy
+	cost    uint64
+}
+
+// NewClassifier creates a Classifier that ignores every plain error until errors are registered
+func NewClassifier() *Classifier {
+	return &Classifier{}
+}
+
+// Sentinel registers the policy and cost for errors that are, or wrap, target, as reported by errors.Is
+func (c *Classifier) Sentinel(target error, policy Policy, cost uint64) *Classifier {
+	c.classifications = append(c.classifications, classification{
+		matches: func(err error) bool {
+			return errors.Is(err, target)
+		},
+		policy: policy,
+		cost:   cost,
+	})
+	return c
+}
+
+// Type registers the policy and cost for errors with the same type as example, anywhere in the chain of wrapped
+// errors
+func (c *Classifier) Type(example error, policy Policy, cost uint64) *Classifier {
+	errorType := reflect.TypeOf(example)
+	c.classifications = append(c.classifications, classification{
+		matches: func(err error) bool {
+			return hasType(err, errorType)
+		},
+		policy: policy,
+		cost:   cost,
+	})
+	return c
+}
+
+// Otherwise sets the policy and cost for errors that match nothing registered, such as to record every error
+func (c *Classifier) Otherwise(policy Policy, cost uint64) *Classifier {
+	c.otherwise = classification{
+		policy: policy,
+		cost:   cost,
+	}
+	return c
+}
+
+// Classify returns the tripping error to record for err, or nil if err should not count against the breaker.
+// A nil Classifier only recognizes tripping errors, just like breakers did before classifiers existed
+func (c *Classifier) Classify(err error) *Error {
+	if err == nil || IsUnrecorded(err) {
+		return nil
+	}
+	if trippingErr, ok := As(err); ok {
+		return trippingErr
+	}
+	if c == nil {
+		return nil
+	}
+	chosen := c.otherwise
+	for _, classification := range c.classifications {
+		if classification.matches(err) {
+			chosen = classification
+			break
+		}
+	}
+	if chosen.policy != Record {
+		return nil
+	}
+	return NewWithCost(err, chosen.cost)
+}
+
+// hasType is true if err, or any error it wraps, has the type
+func hasType(err error, errorType reflect.Type) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if reflect.TypeOf(err) == errorType {
+			return true
+		}
+	}
+	return false
+}
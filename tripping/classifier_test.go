@@ -0,0 +1,87 @@
+package tripping_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"testing"
+)
+
+func TestClassifier_Classify(t *testing.T) {
+	explicit := tripping.NewWithCost(wrappedError, 7)
+	cases := map[string]struct {
+		classifier   *tripping.Classifier
+		input        error
+		expected     bool
+		expectedCost uint64
+	}{
+		"nil error": {
+			classifier: tripping.NewClassifier().Otherwise(tripping.Record, 1),
+		},
+		"nil classifier ignores plain errors": {
+			input: wrappedError,
+		},
+		"nil classifier records tripping errors": {
+			input:        explicit,
+			expected:     true,
+			expectedCost: 7,
+		},
+		"unregistered errors are ignored": {
+			classifier: tripping.NewClassifier(),
+			input:      wrappedError,
+		},
+		"otherwise": {
+			classifier:   tripping.NewClassifier().Otherwise(tripping.Record, 3),
+			input:        wrappedError,
+			expected:     true,
+			expectedCost: 3,
+		},
+		"sentinel": {
+			classifier:   tripping.NewClassifier().Sentinel(context.DeadlineExceeded, tripping.Record, 2),
+			input:        fmt.Errorf("calling backend: %w", context.DeadlineExceeded),
+			expected:     true,
+			expectedCost: 2,
+		},
+		"ignored sentinel": {
+			classifier: tripping.NewClassifier().
+				Sentinel(context.Canceled, tripping.Ignore, 0).
+				Otherwise(tripping.Record, 1),
+			input: context.Canceled,
+		},
+		"type": {
+			classifier:   tripping.NewClassifier().Type(timeoutError{}, tripping.Record, 4),
+			input:        fmt.Errorf("calling backend: %w", timeoutError{}),
+			expected:     true,
+			expectedCost: 4,
+		},
+		"first registration wins": {
+			classifier: tripping.NewClassifier().
+				Type(timeoutError{}, tripping.Record, 4).
+				Type(timeoutError{}, tripping.Ignore, 0),
+			input:        timeoutError{},
+			expected:     true,
+			expectedCost: 4,
+		},
+		"tripping errors are always recorded": {
+			classifier:   tripping.NewClassifier().Sentinel(wrappedError, tripping.Ignore, 0),
+			input:        fmt.Errorf("calling backend: %w", explicit),
+			expected:     true,
+			expectedCost: 7,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			actual := dt.classifier.Classify(dt.input)
+			if !dt.expected {
+				g.Expect(actual).Should(BeNil())
+				return
+			}
+			g.Expect(actual).ShouldNot(BeNil())
+			g.Expect(actual.Cost).Should(Equal(dt.expectedCost))
+			g.Expect(errors.Is(actual, dt.input) || errors.Is(dt.input, actual)).Should(BeTrue())
+		})
+	}
+}
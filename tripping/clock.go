@@ -0,0 +1,35 @@
+package tripping
+
+import "time"
+
+// Clocked is optionally implemented by a Recorder that depends on the time. Breakers call SetClock when they are
+// created, so the Recorder uses the same, possibly simulated, clock as the breaker
+type Clocked interface {
+	SetClock(now func() time.Time)
+}
+
+// SetClock gives now to recorder, and every Recorder it combines, that is Clocked
+func SetClock(recorder Recorder, now func() time.Time) {
+	Walk(recorder, func(recorder Recorder) {
+		if clocked, ok := recorder.(Clocked); ok {
+			clocked.SetClock(now)
+		}
+	})
+}
+
+// clock is embedded by Recorders that depend on the time, it uses time.Now until SetClock is called
+type clock struct {
+	now func() time.Time
+}
+
+func (c *clock) SetClock(now func() time.Time) {
+	c.now = now
+}
+
+// Now is the current time according to the clock
+func (c *clock) Now() time.Time {
+	if c.now == nil {
+		return time.Now()
+	}
+	return c.now()
+}
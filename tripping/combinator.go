@@ -0,0 +1,263 @@
+package tripping
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Both trips only when both a and b trip
+func Both(a, b Recorder) Recorder {
+	return &allRecorder{name: "Both", recorders: []Recorder{a, b}}
+}
+
+// All trips only when every one of the recorders trips
+func All(recorders ...Recorder) Recorder {
+	return &allRecorder{name: "All", recorders: recorders}
+}
+
+// Either trips when either a or b trips
+func Either(a, b Recorder) Recorder {
+	return &anyRecorder{name: "Either", recorders: []Recorder{a, b}}
+}
+
+// Any trips when at least one of the recorders trips
+func Any(recorders ...Recorder) Recorder {
+	return &anyRecorder{name: "Any", recorders: recorders}
+}
+
+// Negate trips when recorder does not, such as to only trip while some other condition is false. Breakers check their
+// Recorder after successes too, so combine it with Both or All rather than using it on its own: a lone Negate of a
+// recorder that is not tripped trips the breaker on a success.
+func Negate(recorder Recorder) Recorder {
+	return &notRecorder{recorder: recorder}
+}
+
+// WithMinCalls only trips once at least minCalls calls were recorded since the breaker last transitioned, so a few
+// failures at low traffic do not trip the breaker
+func WithMinCalls(minCalls uint64, recorder Recorder) Recorder {
+	return &minCallsRecorder{minCalls: minCalls, recorder: recorder}
+}
+
+// ForErrorType only tells recorder about failures with an error of the same type as example, anywhere in the chain of
+// wrapped errors. Calls that failed with any other error are recorded as successes, so ConsecutiveFailures counts
+// consecutive failures of this type.
+//
+// Example:
+// timeouts := ForErrorType(context.DeadlineExceeded, ConsecutiveFailures(5))
+//
+// A nil example matches no failures, so recorder only ever sees successes.
+func ForErrorType(example error, recorder Recorder) Recorder {
+	return &errorTypeRecorder{errorType: reflect.TypeOf(example), recorder: recorder}
+}
+
+// group forwards every call to each of its recorders
+type group []Recorder
+
+func (g group) OnSuccess(latency time.Duration) {
+	for _, recorder := range g {
+		recorder.OnSuccess(latency)
+	}
+}
+
+func (g group) OnFailure(trippingErr *Error, latency time.Duration) {
+	for _, recorder := range g {
+		recorder.OnFailure(trippingErr, latency)
+	}
+}
+
+func (g group) Reset(state State) {
+	for _, recorder := range g {
+		recorder.Reset(state)
+	}
+}
+
+// explain each recorder in the group
+func (g group) explain() []Explanation {
+	explanations := make([]Explanation, len(g))
+	for i, recorder := range g {
+		explanations[i] = Explain(recorder)
+	}
+	return explanations
+}
+
+// allRecorder is the Recorder created by Both and All
+type allRecorder struct {
+	name      string
+	recorders group
+}
+
+func (a *allRecorder) OnSuccess(latency time.Duration) {
+	a.recorders.OnSuccess(latency)
+}
+
+func (a *allRecorder) OnFailure(trippingErr *Error, latency time.Duration) {
+	a.recorders.OnFailure(trippingErr, latency)
+}
+
+func (a *allRecorder) ShouldTrip() bool {
+	for _, recorder := range a.recorders {
+		if !recorder.ShouldTrip() {
+			return false
+		}
+	}
+	return len(a.recorders) > 0
+}
+
+func (a *allRecorder) Reset(state State) {
+	a.recorders.Reset(state)
+}
+
+func (a *allRecorder) Recorders() []Recorder {
+	return a.recorders
+}
+
+func (a *allRecorder) Explain() Explanation {
+	return Explanation{Name: a.name, Tripped: a.ShouldTrip(), Children: a.recorders.explain()}
+}
+
+// anyRecorder is the Recorder created by Either and Any
+type anyRecorder struct {
+	name      string
+	recorders group
+}
+
+func (a *anyRecorder) OnSuccess(latency time.Duration) {
+	a.recorders.OnSuccess(latency)
+}
+
+func (a *anyRecorder) OnFailure(trippingErr *Error, latency time.Duration) {
+	a.recorders.OnFailure(trippingErr, latency)
+}
+
+func (a *anyRecorder) ShouldTrip() bool {
+	for _, recorder := range a.recorders {
+		if recorder.ShouldTrip() {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *anyRecorder) Reset(state State) {
+	a.recorders.Reset(state)
+}
+
+func (a *anyRecorder) Recorders() []Recorder {
+	return a.recorders
+}
+
+func (a *anyRecorder) Explain() Explanation {
+	return Explanation{Name: a.name, Tripped: a.ShouldTrip(), Children: a.recorders.explain()}
+}
+
+// notRecorder is the Recorder created by Negate
+type notRecorder struct {
+	recorder Recorder
+}
+
+func (n *notRecorder) OnSuccess(latency time.Duration) {
+	n.recorder.OnSuccess(latency)
+}
+
+func (n *notRecorder) OnFailure(trippingErr *Error, latency time.Duration) {
+	n.recorder.OnFailure(trippingErr, latency)
+}
+
+func (n *notRecorder) ShouldTrip() bool {
+	return !n.recorder.ShouldTrip()
+}
+
+func (n *notRecorder) Reset(state State) {
+	n.recorder.Reset(state)
+}
+
+func (n *notRecorder) Recorders() []Recorder {
+	return []Recorder{n.recorder}
+}
+
+func (n *notRecorder) Explain() Explanation {
+	return Explanation{Name: "Negate", Tripped: n.ShouldTrip(), Children: []Explanation{Explain(n.recorder)}}
+}
+
+// minCallsRecorder is the Recorder created by WithMinCalls
+type minCallsRecorder struct {
+	minCalls uint64
+	calls    uint64
+	recorder Recorder
+}
+
+func (m *minCallsRecorder) OnSuccess(latency time.Duration) {
+	m.calls++
+	m.recorder.OnSuccess(latency)
+}
+
+func (m *minCallsRecorder) OnFailure(trippingErr *Error, latency time.Duration) {
+	m.calls++
+	m.recorder.OnFailure(trippingErr, latency)
+}
+
+func (m *minCallsRecorder) ShouldTrip() bool {
+	return m.calls >= m.minCalls && m.recorder.ShouldTrip()
+}
+
+func (m *minCallsRecorder) Reset(state State) {
+	m.calls = 0
+	m.recorder.Reset(state)
+}
+
+func (m *minCallsRecorder) Recorders() []Recorder {
+	return []Recorder{m.recorder}
+}
+
+func (m *minCallsRecorder) Explain() Explanation {
+	return Explanation{
+		Name:     "WithMinCalls",
+		Tripped:  m.ShouldTrip(),
+		Detail:   fmt.Sprintf("%d calls, minimum %d", m.calls, m.minCalls),
+		Children: []Explanation{Explain(m.recorder)},
+	}
+}
+
+// errorTypeRecorder is the Recorder created by ForErrorType
+type errorTypeRecorder struct {
+	errorType reflect.Type
+	recorder  Recorder
+}
+
+func (e *errorTypeRecorder) OnSuccess(latency time.Duration) {
+	e.recorder.OnSuccess(latency)
+}
+
+func (e *errorTypeRecorder) OnFailure(trippingErr *Error, latency time.Duration) {
+	if hasType(trippingErr.Err, e.errorType) {
+		e.recorder.OnFailure(trippingErr, latency)
+	} else {
+		e.recorder.OnSuccess(latency)
+	}
+}
+
+func (e *errorTypeRecorder) ShouldTrip() bool {
+	return e.recorder.ShouldTrip()
+}
+
+func (e *errorTypeRecorder) Reset(state State) {
+	e.recorder.Reset(state)
+}
+
+func (e *errorTypeRecorder) Recorders() []Recorder {
+	return []Recorder{e.recorder}
+}
+
+func (e *errorTypeRecorder) Explain() Explanation {
+	detail := "nil"
+	if e.errorType != nil {
+		detail = e.errorType.String()
+	}
+	return Explanation{
+		Name:     "ForErrorType",
+		Tripped:  e.ShouldTrip(),
+		Detail:   detail,
+		Children: []Explanation{Explain(e.recorder)},
+	}
+}
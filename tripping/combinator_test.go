@@ -0,0 +1,293 @@
+package tripping_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"testing"
+	"time"
+)
+
+var wrappedError = errors.New("wrapped")
+
+// fixedRecorder trips if tripped is set, it ignores what it is told
+type fixedRecorder struct {
+	tripped bool
+}
+
+func (f *fixedRecorder) OnSuccess(_ time.Duration)                    {}
+func (f *fixedRecorder) OnFailure(_ *tripping.Error, _ time.Duration) {}
+func (f *fixedRecorder) ShouldTrip() bool                             { return f.tripped }
+func (f *fixedRecorder) Reset(_ tripping.State)                       {}
+
+var (
+	trips      = &fixedRecorder{tripped: true}
+	neverTrips = &fixedRecorder{}
+)
+
+func failTimes(recorder tripping.Recorder, err error, times int) {
+	for i := 0; i < times; i++ {
+		recorder.OnFailure(tripping.New(err), time.Second)
+	}
+}
+
+func succeedTimes(recorder tripping.Recorder, times int) {
+	for i := 0; i < times; i++ {
+		recorder.OnSuccess(time.Second)
+	}
+}
+
+func TestCombinators(t *testing.T) {
+	cases := map[string]struct {
+		recorder tripping.Recorder
+		expected bool
+	}{
+		"and both trip": {
+			recorder: tripping.Both(trips, trips),
+			expected: true,
+		},
+		"and one trips": {
+			recorder: tripping.Both(trips, neverTrips),
+		},
+		"all trip": {
+			recorder: tripping.All(trips, trips, trips),
+			expected: true,
+		},
+		"all but one trip": {
+			recorder: tripping.All(trips, neverTrips, trips),
+		},
+		"all of nothing": {
+			recorder: tripping.All(),
+		},
+		"or one trips": {
+			recorder: tripping.Either(neverTrips, trips),
+			expected: true,
+		},
+		"or neither trips": {
+			recorder: tripping.Either(neverTrips, neverTrips),
+		},
+		"any trips": {
+			recorder: tripping.Any(neverTrips, neverTrips, trips),
+			expected: true,
+		},
+		"any of nothing": {
+			recorder: tripping.Any(),
+		},
+		"not tripped": {
+			recorder: tripping.Negate(neverTrips),
+			expected: true,
+		},
+		"not trips": {
+			recorder: tripping.Negate(trips),
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(dt.recorder.ShouldTrip()).Should(Equal(dt.expected))
+		})
+	}
+}
+
+func TestConsecutiveFailures(t *testing.T) {
+	cases := map[string]struct {
+		record   func(recorder tripping.Recorder)
+		expected bool
+	}{
+		"too few failures": {
+			record: func(recorder tripping.Recorder) {
+				failTimes(recorder, wrappedError, 2)
+			},
+		},
+		"enough failures": {
+			record: func(recorder tripping.Recorder) {
+				failTimes(recorder, wrappedError, 3)
+			},
+			expected: true,
+		},
+		"interrupted by a success": {
+			record: func(recorder tripping.Recorder) {
+				failTimes(recorder, wrappedError, 2)
+				succeedTimes(recorder, 1)
+				failTimes(recorder, wrappedError, 2)
+			},
+		},
+		"reset": {
+			record: func(recorder tripping.Recorder) {
+				failTimes(recorder, wrappedError, 3)
+				recorder.Reset(tripping.Closed)
+			},
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			subject := tripping.ConsecutiveFailures(3)
+			dt.record(subject)
+			g.Expect(subject.ShouldTrip()).Should(Equal(dt.expected))
+		})
+	}
+}
+
+func TestConsecutiveFailures_Zero(t *testing.T) {
+	g := NewWithT(t)
+	subject := tripping.ConsecutiveFailures(0)
+	succeedTimes(subject, 1)
+	g.Expect(subject.ShouldTrip()).Should(BeFalse())
+	failTimes(subject, wrappedError, 1)
+	g.Expect(subject.ShouldTrip()).Should(BeTrue())
+}
+
+func TestFailureRate(t *testing.T) {
+	cases := map[string]struct {
+		record   func(recorder tripping.Recorder)
+		expected bool
+	}{
+		"no calls": {
+			record: func(recorder tripping.Recorder) {},
+		},
+		"at the threshold": {
+			record: func(recorder tripping.Recorder) {
+				failTimes(recorder, wrappedError, 2)
+				succeedTimes(recorder, 2)
+			},
+		},
+		"over the threshold": {
+			record: func(recorder tripping.Recorder) {
+				failTimes(recorder, wrappedError, 3)
+				succeedTimes(recorder, 1)
+			},
+			expected: true,
+		},
+		"old failures leave the window": {
+			record: func(recorder tripping.Recorder) {
+				failTimes(recorder, wrappedError, 4)
+				succeedTimes(recorder, 2)
+			},
+		},
+		"reset": {
+			record: func(recorder tripping.Recorder) {
+				failTimes(recorder, wrappedError, 4)
+				recorder.Reset(tripping.Closed)
+				succeedTimes(recorder, 1)
+			},
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			subject := tripping.FailureRate(0.5, 4)
+			dt.record(subject)
+			g.Expect(subject.ShouldTrip()).Should(Equal(dt.expected))
+		})
+	}
+}
+
+func TestWithMinCalls(t *testing.T) {
+	cases := map[string]struct {
+		record   func(recorder tripping.Recorder)
+		expected bool
+	}{
+		"too few calls": {
+			record: func(recorder tripping.Recorder) {
+				failTimes(recorder, wrappedError, 4)
+			},
+		},
+		"enough calls": {
+			record: func(recorder tripping.Recorder) {
+				failTimes(recorder, wrappedError, 5)
+			},
+			expected: true,
+		},
+		"reset": {
+			record: func(recorder tripping.Recorder) {
+				failTimes(recorder, wrappedError, 5)
+				recorder.Reset(tripping.Closed)
+				failTimes(recorder, wrappedError, 1)
+			},
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			subject := tripping.WithMinCalls(5, tripping.FailureRate(0.5, 10))
+			dt.record(subject)
+			g.Expect(subject.ShouldTrip()).Should(Equal(dt.expected))
+		})
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string {
+	return "timed out"
+}
+
+func TestForErrorType(t *testing.T) {
+	cases := map[string]struct {
+		record   func(recorder tripping.Recorder)
+		expected bool
+	}{
+		"matching errors": {
+			record: func(recorder tripping.Recorder) {
+				failTimes(recorder, timeoutError{}, 2)
+			},
+			expected: true,
+		},
+		"wrapped matching errors": {
+			record: func(recorder tripping.Recorder) {
+				failTimes(recorder, fmt.Errorf("calling backend: %w", timeoutError{}), 2)
+			},
+			expected: true,
+		},
+		"other errors count as successes": {
+			record: func(recorder tripping.Recorder) {
+				failTimes(recorder, timeoutError{}, 1)
+				failTimes(recorder, errors.New("other"), 1)
+				failTimes(recorder, timeoutError{}, 1)
+			},
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			subject := tripping.ForErrorType(timeoutError{}, tripping.ConsecutiveFailures(2))
+			dt.record(subject)
+			g.Expect(subject.ShouldTrip()).Should(Equal(dt.expected))
+		})
+	}
+}
+
+func TestForErrorType_Nil(t *testing.T) {
+	g := NewWithT(t)
+	subject := tripping.ForErrorType(nil, tripping.ConsecutiveFailures(1))
+	failTimes(subject, wrappedError, 1)
+	g.Expect(subject.ShouldTrip()).Should(BeFalse())
+	g.Expect(tripping.Explain(subject).Detail).Should(Equal("nil"))
+}
+
+func TestExplain(t *testing.T) {
+	g := NewWithT(t)
+	subject := tripping.Either(
+		tripping.WithMinCalls(4, tripping.FailureRate(0.5, 4)),
+		tripping.ForErrorType(context.DeadlineExceeded, tripping.ConsecutiveFailures(3)),
+	)
+	failTimes(subject, errors.New("failed"), 3)
+	succeedTimes(subject, 1)
+
+	g.Expect(tripping.Explain(subject).String()).Should(Equal(`Either: tripped
+  WithMinCalls: tripped (4 calls, minimum 4)
+    FailureRate: tripped (3 of 4 calls failed, threshold 50%)
+  ForErrorType: ok (context.deadlineExceededError)
+    ConsecutiveFailures: ok (0 of 3 failures in a row)`))
+}
+
+func TestExplain_NotAnExplainer(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(tripping.Explain(trips)).Should(Equal(tripping.Explanation{
+		Name:    "*tripping_test.fixedRecorder",
+		Tripped: true,
+	}))
+}
@@ -0,0 +1,45 @@
+package tripping
+
+import (
+	"fmt"
+	"time"
+)
+
+// ConsecutiveFailures trips once the last n calls all failed. n is at least 1, as no failures in a row would trip
+// the breaker on a success.
+func ConsecutiveFailures(n uint64) Recorder {
+	if n == 0 {
+		n = 1
+	}
+	return &consecutiveFailuresRecorder{n: n}
+}
+
+// consecutiveFailuresRecorder is the Recorder created by ConsecutiveFailures
+type consecutiveFailuresRecorder struct {
+	n        uint64
+	failures uint64
+}
+
+func (c *consecutiveFailuresRecorder) OnSuccess(_ time.Duration) {
+	c.failures = 0
+}
+
+func (c *consecutiveFailuresRecorder) OnFailure(_ *Error, _ time.Duration) {
+	c.failures++
+}
+
+func (c *consecutiveFailuresRecorder) ShouldTrip() bool {
+	return c.failures >= c.n
+}
+
+func (c *consecutiveFailuresRecorder) Reset(_ State) {
+	c.failures = 0
+}
+
+func (c *consecutiveFailuresRecorder) Explain() Explanation {
+	return Explanation{
+		Name:    "ConsecutiveFailures",
+		Tripped: c.ShouldTrip(),
+		Detail:  fmt.Sprintf("%d of %d failures in a row", c.failures, c.n),
+	}
+}
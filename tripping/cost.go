@@ -0,0 +1,32 @@
+package tripping
+
+import "time"
+
+// CostFunc computes the cost of a failure from what the breaker observed, rather than the cost it was created with.
+// err is the error the tripping error converted and category is the tripping error's Category
+type CostFunc func(err error, latency time.Duration, category Category) uint64
+
+// Weigh returns a copy of trippingErr costing what the CostFunc computes. A nil CostFunc keeps the original cost
+func (c CostFunc) Weigh(trippingErr *Error, latency time.Duration) *Error {
+	if c == nil {
+		return trippingErr
+	}
+	weighed := *trippingErr
+	weighed.Cost = c(trippingErr.Err, latency, trippingErr.Category)
+	return &weighed
+}
+
+// LatencyCost charges 1 for every started per of latency, at least 1 and at most max, such as LatencyCost(time.Second, 30)
+// to have a 30-second timeout cost 30 times as much as a fast failure. A max of 0 does not cap the cost
+func LatencyCost(per time.Duration, max uint64) CostFunc {
+	return func(_ error, latency time.Duration, _ Category) uint64 {
+		cost := uint64(1)
+		if per > 0 && latency > per {
+			cost = uint64((latency + per - 1) / per)
+		}
+		if max > 0 && cost > max {
+			cost = max
+		}
+		return cost
+	}
+}
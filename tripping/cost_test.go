@@ -0,0 +1,73 @@
+package tripping_test
+
+import (
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"testing"
+	"time"
+)
+
+func TestLatencyCost(t *testing.T) {
+	cases := map[string]struct {
+		latency  time.Duration
+		expected uint64
+	}{
+		"instant": {
+			expected: 1,
+		},
+		"under one": {
+			latency:  500 * time.Millisecond,
+			expected: 1,
+		},
+		"started second": {
+			latency:  2100 * time.Millisecond,
+			expected: 3,
+		},
+		"capped": {
+			latency:  time.Minute,
+			expected: 10,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			actual := tripping.LatencyCost(time.Second, 10)(wrappedError, dt.latency, "")
+			g.Expect(actual).Should(Equal(dt.expected))
+		})
+	}
+}
+
+func TestLatencyCost_NoCap(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(tripping.LatencyCost(time.Second, 0)(wrappedError, time.Minute, "")).Should(Equal(uint64(60)))
+}
+
+func TestCostFunc_Weigh(t *testing.T) {
+	cases := map[string]struct {
+		costFunc     tripping.CostFunc
+		expectedCost uint64
+	}{
+		"nil keeps the cost": {
+			expectedCost: 2,
+		},
+		"computed": {
+			costFunc: func(err error, latency time.Duration, category tripping.Category) uint64 {
+				if err == wrappedError && category == timedOut {
+					return uint64(latency / time.Second)
+				}
+				return 0
+			},
+			expectedCost: 5,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			original := &tripping.Error{Err: wrappedError, Cost: 2, Category: timedOut}
+			actual := dt.costFunc.Weigh(original, 5*time.Second)
+			g.Expect(actual.Cost).Should(Equal(dt.expectedCost))
+			g.Expect(actual.Category).Should(Equal(timedOut))
+			g.Expect(original.Cost).Should(Equal(uint64(2)))
+		})
+	}
+}
@@ -1,8 +1,14 @@
 package tripping
 
+import "errors"
+
 type Error struct {
 	Err  error
 	Cost uint64
+
+	// Category groups errors that mean the same thing, such as timeouts, so breakers can apply a different
+	// threshold to each. Leave empty for uncategorized errors
+	Category Category
 }
 
 // New converts your error into a tripping error, one the circuit breaker
@@ -21,16 +27,47 @@ func NewWithCost(err error, cost uint64) *Error {
 	}
 }
 
+// NewWithCategory converts your error to a tripping error with a unit cost of 1 in the category
+func NewWithCategory(err error, category Category) *Error {
+	return &Error{
+		Err:      err,
+		Cost:     1,
+		Category: category,
+	}
+}
+
 // Error satisfies the Error interface by returning the wrapped error's string
 func (e *Error) Error() string {
 	return e.Err.Error()
 }
 
-// IsTripping evaluates the error or nil and returns true if this is a tripping error, or false if nil or some other error type
+// Unwrap returns the converted error, so errors.Is and errors.As see through tripping errors
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// IsTripping evaluates the error or nil and returns true if this is a tripping error, or wraps one, or false if nil or
+// some other error type
 func IsTripping(err error) bool {
-	if err == nil {
-		return false
-	}
-	_, ok := err.(*Error)
+	_, ok := As(err)
 	return ok
 }
+
+// As returns the tripping error in err's chain of wrapped errors, if there is one
+func As(err error) (trippingErr *Error, ok bool) {
+	ok = errors.As(err, &trippingErr)
+	return
+}
+
+// Strip returns the error to give back to callers: the converted error if err is a tripping error or was created by
+// Unrecorded, otherwise err.
+// Errors wrapping a tripping error are returned unchanged, use errors.Is or errors.As to inspect them
+func Strip(err error) error {
+	switch stripped := err.(type) {
+	case *Error:
+		return stripped.Err
+	case *unrecordedError:
+		return Strip(stripped.err)
+	}
+	return err
+}
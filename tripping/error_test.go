@@ -2,6 +2,7 @@ package tripping
 
 import (
 	"errors"
+	"fmt"
 	. "github.com/onsi/gomega"
 	"testing"
 )
@@ -52,6 +53,10 @@ func TestIsTripping(t *testing.T) {
 			input:    New(wrappedError),
 			expected: true,
 		},
+		"wrapped tripping": {
+			input:    fmt.Errorf("calling backend: %w", New(wrappedError)),
+			expected: true,
+		},
 	}
 	for caseName, dt := range cases {
 		t.Run(caseName, func(t *testing.T) {
@@ -61,3 +66,36 @@ func TestIsTripping(t *testing.T) {
 		})
 	}
 }
+
+func TestError_Unwrap(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(errors.Is(New(wrappedError), wrappedError)).Should(BeTrue())
+}
+
+func TestStrip(t *testing.T) {
+	trippingErr := New(wrappedError)
+	wrapped := fmt.Errorf("calling backend: %w", trippingErr)
+	cases := map[string]struct {
+		input    error
+		expected error
+	}{
+		"non-tripping": {
+			input:    wrappedError,
+			expected: wrappedError,
+		},
+		"tripping": {
+			input:    trippingErr,
+			expected: wrappedError,
+		},
+		"wrapped tripping": {
+			input:    wrapped,
+			expected: wrapped,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(Strip(dt.input)).Should(Equal(dt.expected))
+		})
+	}
+}
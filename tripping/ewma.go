@@ -0,0 +1,113 @@
+package tripping
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+type EWMAOpts struct {
+	// HalfLife is how long it takes for a call's weight in the averages to halve
+	HalfLife time.Duration
+
+	// FailureRateThreshold trips the breaker once the average failure rate exceeds it, such as 0.5 to trip when over
+	// half of calls are failing. Leave 0 to only trip on latency
+	FailureRateThreshold float64
+
+	// LatencyThreshold trips the breaker once the average latency exceeds it. Leave 0 to only trip on the failure rate
+	LatencyThreshold time.Duration
+
+	// MinSamples is how many calls must be recorded since the breaker last transitioned before it may trip
+	MinSamples uint64
+}
+
+// EWMA trips once the exponentially weighted moving average of the failure rate or latency crosses its threshold.
+// Unlike FailureRate, every call is weighed by how recently it was made rather than whether it is in a window,
+// so the average moves smoothly even at low traffic
+func EWMA(opts EWMAOpts) Recorder {
+	return &ewmaRecorder{
+		opts: opts,
+	}
+}
+
+// ewmaRecorder is the Recorder created by EWMA. It keeps sums of every call's outcome and latency, and of their
+// weights, decaying them all by half every HalfLife. The averages are the weighted sums divided by the total weight
+type ewmaRecorder struct {
+	clock
+	opts EWMAOpts
+
+	samples   uint64
+	lastAt    time.Time
+	weight    float64
+	failures  float64
+	latencies float64
+}
+
+func (e *ewmaRecorder) OnSuccess(latency time.Duration) {
+	e.record(0, latency)
+}
+
+func (e *ewmaRecorder) OnFailure(_ *Error, latency time.Duration) {
+	e.record(1, latency)
+}
+
+// record decays what was recorded so far by how long it has been since the last call, then adds the call
+func (e *ewmaRecorder) record(failed float64, latency time.Duration) {
+	now := e.Now()
+	if e.samples > 0 && e.opts.HalfLife > 0 {
+		elapsed := now.Sub(e.lastAt)
+		if elapsed > 0 {
+			decay := math.Exp2(-float64(elapsed) / float64(e.opts.HalfLife))
+			e.weight *= decay
+			e.failures *= decay
+			e.latencies *= decay
+		}
+	}
+	e.weight++
+	e.failures += failed
+	e.latencies += float64(latency)
+	e.lastAt = now
+	e.samples++
+}
+
+// failureRate is the weighted average of calls that failed
+func (e *ewmaRecorder) failureRate() float64 {
+	if e.weight == 0 {
+		return 0
+	}
+	return e.failures / e.weight
+}
+
+// latency is the weighted average latency of calls
+func (e *ewmaRecorder) latency() time.Duration {
+	if e.weight == 0 {
+		return 0
+	}
+	return time.Duration(e.latencies / e.weight)
+}
+
+func (e *ewmaRecorder) ShouldTrip() bool {
+	if e.samples == 0 || e.samples < e.opts.MinSamples {
+		return false
+	}
+	if e.opts.FailureRateThreshold > 0 && e.failureRate() > e.opts.FailureRateThreshold {
+		return true
+	}
+	return e.opts.LatencyThreshold > 0 && e.latency() > e.opts.LatencyThreshold
+}
+
+func (e *ewmaRecorder) Reset(_ State) {
+	e.samples = 0
+	e.weight = 0
+	e.failures = 0
+	e.latencies = 0
+}
+
+func (e *ewmaRecorder) Explain() Explanation {
+	return Explanation{
+		Name:    "EWMA",
+		Tripped: e.ShouldTrip(),
+		Detail: fmt.Sprintf("failure rate %.0f%%, latency %s over %d calls",
+			e.failureRate()*100, e.latency().Round(time.Millisecond), e.samples),
+	}
+}
@@ -0,0 +1,101 @@
+package tripping_test
+
+import (
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"testing"
+	"time"
+)
+
+func TestEWMA(t *testing.T) {
+	cases := map[string]struct {
+		opts     tripping.EWMAOpts
+		record   func(recorder tripping.Recorder, advance func(time.Duration))
+		expected bool
+	}{
+		"no calls": {
+			opts:   tripping.EWMAOpts{HalfLife: time.Minute, FailureRateThreshold: 0.5},
+			record: func(recorder tripping.Recorder, advance func(time.Duration)) {},
+		},
+		"failure rate over the threshold": {
+			opts: tripping.EWMAOpts{HalfLife: time.Minute, FailureRateThreshold: 0.5},
+			record: func(recorder tripping.Recorder, advance func(time.Duration)) {
+				succeedTimes(recorder, 1)
+				failTimes(recorder, wrappedError, 2)
+			},
+			expected: true,
+		},
+		"failure rate at the threshold": {
+			opts: tripping.EWMAOpts{HalfLife: time.Minute, FailureRateThreshold: 0.5},
+			record: func(recorder tripping.Recorder, advance func(time.Duration)) {
+				succeedTimes(recorder, 2)
+				failTimes(recorder, wrappedError, 2)
+			},
+		},
+		"old successes decay": {
+			opts: tripping.EWMAOpts{HalfLife: time.Minute, FailureRateThreshold: 0.5},
+			record: func(recorder tripping.Recorder, advance func(time.Duration)) {
+				succeedTimes(recorder, 4)
+				advance(3 * time.Minute)
+				failTimes(recorder, wrappedError, 1)
+			},
+			expected: true,
+		},
+		"too few samples": {
+			opts: tripping.EWMAOpts{HalfLife: time.Minute, FailureRateThreshold: 0.5, MinSamples: 3},
+			record: func(recorder tripping.Recorder, advance func(time.Duration)) {
+				failTimes(recorder, wrappedError, 2)
+			},
+		},
+		"latency over the threshold": {
+			opts: tripping.EWMAOpts{HalfLife: time.Minute, LatencyThreshold: 500 * time.Millisecond},
+			record: func(recorder tripping.Recorder, advance func(time.Duration)) {
+				succeedTimes(recorder, 1)
+			},
+			expected: true,
+		},
+		"latency under the threshold": {
+			opts: tripping.EWMAOpts{HalfLife: time.Minute, LatencyThreshold: 2 * time.Second},
+			record: func(recorder tripping.Recorder, advance func(time.Duration)) {
+				failTimes(recorder, wrappedError, 1)
+			},
+		},
+		"reset": {
+			opts: tripping.EWMAOpts{HalfLife: time.Minute, FailureRateThreshold: 0.5},
+			record: func(recorder tripping.Recorder, advance func(time.Duration)) {
+				failTimes(recorder, wrappedError, 2)
+				recorder.Reset(tripping.Closed)
+				succeedTimes(recorder, 1)
+			},
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+			subject := tripping.EWMA(dt.opts)
+			tripping.SetClock(subject, func() time.Time {
+				return now
+			})
+			dt.record(subject, func(d time.Duration) {
+				now = now.Add(d)
+			})
+			g.Expect(subject.ShouldTrip()).Should(Equal(dt.expected))
+		})
+	}
+}
+
+func TestSetClock_Combinators(t *testing.T) {
+	g := NewWithT(t)
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	subject := tripping.Either(neverTrips, tripping.Negate(tripping.Negate(
+		tripping.EWMA(tripping.EWMAOpts{HalfLife: time.Minute, FailureRateThreshold: 0.5}),
+	)))
+	tripping.SetClock(subject, func() time.Time {
+		return now
+	})
+	succeedTimes(subject, 4)
+	now = now.Add(3 * time.Minute)
+	failTimes(subject, wrappedError, 1)
+	g.Expect(subject.ShouldTrip()).Should(BeTrue())
+}
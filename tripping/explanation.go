@@ -0,0 +1,66 @@
+package tripping
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Explanation describes why a Recorder decided to trip, or not. Combinators explain themselves as a tree, with a child
+// for each Recorder they combine
+type Explanation struct {
+	// Name of the Recorder, such as "FailureRate"
+	Name string
+
+	// Tripped is what ShouldTrip returned
+	Tripped bool
+
+	// Detail is what the decision was based on, such as "6 of 10 calls failed"
+	Detail string
+
+	Children []Explanation
+}
+
+// Explainer is optionally implemented by a Recorder to describe its decision. Every Recorder in this package
+// implements it
+type Explainer interface {
+	Explain() Explanation
+}
+
+// Explain describes recorder's decision, even if it does not implement Explainer.
+// Like all Recorder methods, do not call this concurrently with the breaker using the recorder, instead use the
+// breaker's TripExplanation
+func Explain(recorder Recorder) Explanation {
+	if explainer, ok := recorder.(Explainer); ok {
+		return explainer.Explain()
+	}
+	return Explanation{
+		Name:    fmt.Sprintf("%T", recorder),
+		Tripped: recorder.ShouldTrip(),
+	}
+}
+
+// String renders the explanation as an indented tree, one Recorder per line
+func (e Explanation) String() string {
+	var builder strings.Builder
+	e.write(&builder, 0)
+	return builder.String()
+}
+
+// write renders the explanation at depth, followed by its children
+func (e Explanation) write(builder *strings.Builder, depth int) {
+	if depth > 0 {
+		builder.WriteString("\n")
+	}
+	builder.WriteString(strings.Repeat("  ", depth))
+	verdict := "ok"
+	if e.Tripped {
+		verdict = "tripped"
+	}
+	_, _ = fmt.Fprintf(builder, "%s: %s", e.Name, verdict)
+	if e.Detail != "" {
+		_, _ = fmt.Fprintf(builder, " (%s)", e.Detail)
+	}
+	for _, child := range e.Children {
+		child.write(builder, depth+1)
+	}
+}
@@ -0,0 +1,103 @@
+package tripping
+
+import (
+	"fmt"
+	"time"
+)
+
+// FailureRate trips once more than threshold of the last windowSize calls failed, such as 0.5 to trip when over half
+// of them failed. Fewer than windowSize calls may have been made since the breaker last transitioned, so combine this
+// with WithMinCalls to avoid tripping on the first failure, or use WilsonFailureRate.
+func FailureRate(threshold float64, windowSize int) Recorder {
+	return &failureRateRecorder{
+		threshold: threshold,
+		window:    newOutcomeWindow(windowSize),
+	}
+}
+
+// failureRateRecorder is the Recorder created by FailureRate
+type failureRateRecorder struct {
+	threshold float64
+	window    outcomeWindow
+}
+
+func (f *failureRateRecorder) OnSuccess(_ time.Duration) {
+	f.window.record(false)
+}
+
+func (f *failureRateRecorder) OnFailure(_ *Error, _ time.Duration) {
+	f.window.record(true)
+}
+
+func (f *failureRateRecorder) ShouldTrip() bool {
+	return f.window.rate() > f.threshold
+}
+
+func (f *failureRateRecorder) Reset(_ State) {
+	f.window.reset()
+}
+
+func (f *failureRateRecorder) Explain() Explanation {
+	return Explanation{
+		Name:    "FailureRate",
+		Tripped: f.ShouldTrip(),
+		Detail:  fmt.Sprintf("%s, threshold %.0f%%", &f.window, f.threshold*100),
+	}
+}
+
+// outcomeWindow is a ring of the outcomes of the last calls
+type outcomeWindow struct {
+	// outcomes of the calls, true if the call failed
+	outcomes []bool
+	next     int
+	failures int
+}
+
+func newOutcomeWindow(size int) outcomeWindow {
+	if size <= 0 {
+		size = 1
+	}
+	return outcomeWindow{
+		outcomes: make([]bool, 0, size),
+	}
+}
+
+// record adds the outcome, replacing the oldest once the window is full
+func (w *outcomeWindow) record(failed bool) {
+	if failed {
+		w.failures++
+	}
+	if len(w.outcomes) < cap(w.outcomes) {
+		w.outcomes = append(w.outcomes, failed)
+		return
+	}
+	if w.outcomes[w.next] {
+		w.failures--
+	}
+	w.outcomes[w.next] = failed
+	w.next = (w.next + 1) % len(w.outcomes)
+}
+
+// calls is the number of calls in the window
+func (w *outcomeWindow) calls() int {
+	return len(w.outcomes)
+}
+
+// rate is the fraction of calls in the window that failed
+func (w *outcomeWindow) rate() float64 {
+	if len(w.outcomes) == 0 {
+		return 0
+	}
+	return float64(w.failures) / float64(len(w.outcomes))
+}
+
+func (w *outcomeWindow) reset() {
+	w.outcomes = w.outcomes[:0]
+	w.next = 0
+	w.failures = 0
+}
+
+// String describes the window, such as "6 of 10 calls failed"
+func (w *outcomeWindow) String() string {
+	return fmt.Sprintf("%d of %d calls failed", w.failures, len(w.outcomes))
+}
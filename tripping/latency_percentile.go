@@ -0,0 +1,148 @@
+package tripping
+
+import (
+	"fmt"
+	"github.com/wojnosystems/go-circuit-breaker/quantile"
+	"strings"
+	"time"
+)
+
+const (
+	defaultLatencyPercentile        = 0.99
+	defaultLatencyPercentileWindow  = time.Minute
+	defaultLatencyPercentileBuckets = 6
+)
+
+// defaultReportedPercentiles are reported in snapshots unless LatencyPercentileOpts.Reported is set
+var defaultReportedPercentiles = []float64{0.5, 0.9, 0.99}
+
+type LatencyPercentileOpts struct {
+	// Percentile of latency compared against the Budget, such as 0.99. Defaults to 0.99
+	Percentile float64
+
+	// Budget trips the breaker once the Percentile of latency exceeds it
+	Budget time.Duration
+
+	// Window is how far back latencies are tracked. Defaults to 1 minute
+	Window time.Duration
+
+	// Buckets the Window is divided into, latencies expire a whole bucket at a time. Defaults to 6
+	Buckets int
+
+	// MinSamples is how many calls must be in the Window before the breaker may trip
+	MinSamples uint64
+
+	// RelativeAccuracy of the estimated percentiles, see quantile.NewSketch. Defaults to 1%
+	RelativeAccuracy float64
+
+	// Reported percentiles appear in breakers' snapshots. Defaults to the 50th, 90th and 99th
+	Reported []float64
+}
+
+// Percentile is the latency below which Quantile of calls completed
+type Percentile struct {
+	Quantile float64
+	Latency  time.Duration
+}
+
+// LatencyPercentiler is implemented by Recorders that track latency percentiles, breakers report them in their
+// snapshots
+type LatencyPercentiler interface {
+	LatencyPercentiles() []Percentile
+}
+
+// FindLatencyPercentiles returns the percentiles of the first LatencyPercentiler in recorder's tree, see Walk
+func FindLatencyPercentiles(recorder Recorder) (percentiles []Percentile, ok bool) {
+	Walk(recorder, func(recorder Recorder) {
+		if percentiler, isPercentiler := recorder.(LatencyPercentiler); isPercentiler && !ok {
+			percentiles, ok = percentiler.LatencyPercentiles(), true
+		}
+	})
+	return
+}
+
+// LatencyPercentile trips once a percentile of the latency of calls, failed or not, exceeds a budget, such as when
+// the 99th percentile over the last minute exceeds 2 seconds. Percentiles are estimated with a quantile.Window,
+// so memory does not grow with traffic. The window is cleared each time the breaker closes
+func LatencyPercentile(opts LatencyPercentileOpts) Recorder {
+	if opts.Percentile <= 0 || opts.Percentile > 1 {
+		opts.Percentile = defaultLatencyPercentile
+	}
+	if opts.Window <= 0 {
+		opts.Window = defaultLatencyPercentileWindow
+	}
+	if opts.Buckets <= 0 {
+		opts.Buckets = defaultLatencyPercentileBuckets
+	}
+	if len(opts.Reported) == 0 {
+		opts.Reported = defaultReportedPercentiles
+	}
+	return &latencyPercentileRecorder{
+		opts:      opts,
+		latencies: quantile.NewWindow(opts.Window, opts.Buckets, opts.RelativeAccuracy),
+	}
+}
+
+// latencyPercentileRecorder is the Recorder created by LatencyPercentile
+type latencyPercentileRecorder struct {
+	clock
+	opts      LatencyPercentileOpts
+	latencies *quantile.Window
+}
+
+func (l *latencyPercentileRecorder) OnSuccess(latency time.Duration) {
+	l.latencies.Add(l.Now(), float64(latency))
+}
+
+func (l *latencyPercentileRecorder) OnFailure(_ *Error, latency time.Duration) {
+	l.latencies.Add(l.Now(), float64(latency))
+}
+
+// percentile of the latencies in the sketch
+func percentile(sketch *quantile.Sketch, q float64) time.Duration {
+	return time.Duration(sketch.Quantile(q))
+}
+
+func (l *latencyPercentileRecorder) ShouldTrip() bool {
+	sketch := l.latencies.Sketch(l.Now())
+	return l.shouldTrip(sketch)
+}
+
+func (l *latencyPercentileRecorder) shouldTrip(sketch *quantile.Sketch) bool {
+	if sketch.Count() == 0 || sketch.Count() < l.opts.MinSamples {
+		return false
+	}
+	return percentile(sketch, l.opts.Percentile) > l.opts.Budget
+}
+
+// Reset clears the window when the breaker closes, so the latencies that tripped it do not trip it again
+func (l *latencyPercentileRecorder) Reset(state State) {
+	if state == Closed {
+		l.latencies.Reset()
+	}
+}
+
+func (l *latencyPercentileRecorder) LatencyPercentiles() []Percentile {
+	sketch := l.latencies.Sketch(l.Now())
+	percentiles := make([]Percentile, len(l.opts.Reported))
+	for i, q := range l.opts.Reported {
+		percentiles[i] = Percentile{Quantile: q, Latency: percentile(sketch, q)}
+	}
+	return percentiles
+}
+
+func (l *latencyPercentileRecorder) Explain() Explanation {
+	sketch := l.latencies.Sketch(l.Now())
+	return Explanation{
+		Name:    "LatencyPercentile",
+		Tripped: l.shouldTrip(sketch),
+		Detail: fmt.Sprintf("p%s %s over %d calls, budget %s",
+			formatQuantile(l.opts.Percentile), percentile(sketch, l.opts.Percentile).Round(time.Millisecond),
+			sketch.Count(), l.opts.Budget),
+	}
+}
+
+// formatQuantile as a percentile, such as 99 or 99.9
+func formatQuantile(q float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.3f", q*100), "0"), ".")
+}
@@ -0,0 +1,111 @@
+package tripping_test
+
+import (
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"testing"
+	"time"
+)
+
+func succeedAfter(recorder tripping.Recorder, latency time.Duration, times int) {
+	for i := 0; i < times; i++ {
+		recorder.OnSuccess(latency)
+	}
+}
+
+func TestLatencyPercentile(t *testing.T) {
+	cases := map[string]struct {
+		record   func(recorder tripping.Recorder, advance func(time.Duration))
+		expected bool
+	}{
+		"no calls": {
+			record: func(recorder tripping.Recorder, advance func(time.Duration)) {},
+		},
+		"within budget": {
+			record: func(recorder tripping.Recorder, advance func(time.Duration)) {
+				succeedAfter(recorder, 100*time.Millisecond, 99)
+				succeedAfter(recorder, 5*time.Second, 1)
+			},
+		},
+		"over budget": {
+			record: func(recorder tripping.Recorder, advance func(time.Duration)) {
+				succeedAfter(recorder, 100*time.Millisecond, 90)
+				succeedAfter(recorder, 5*time.Second, 10)
+			},
+			expected: true,
+		},
+		"failures count": {
+			record: func(recorder tripping.Recorder, advance func(time.Duration)) {
+				succeedAfter(recorder, 100*time.Millisecond, 90)
+				for i := 0; i < 10; i++ {
+					recorder.OnFailure(tripping.New(wrappedError), 5*time.Second)
+				}
+			},
+			expected: true,
+		},
+		"too few samples": {
+			record: func(recorder tripping.Recorder, advance func(time.Duration)) {
+				succeedAfter(recorder, 5*time.Second, 9)
+			},
+		},
+		"slow calls expire": {
+			record: func(recorder tripping.Recorder, advance func(time.Duration)) {
+				succeedAfter(recorder, 5*time.Second, 10)
+				advance(2 * time.Minute)
+				succeedAfter(recorder, 100*time.Millisecond, 10)
+			},
+		},
+		"cleared when closed": {
+			record: func(recorder tripping.Recorder, advance func(time.Duration)) {
+				succeedAfter(recorder, 5*time.Second, 10)
+				recorder.Reset(tripping.Closed)
+			},
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+			subject := tripping.LatencyPercentile(tripping.LatencyPercentileOpts{
+				Budget:     time.Second,
+				MinSamples: 10,
+			})
+			tripping.SetClock(subject, func() time.Time {
+				return now
+			})
+			dt.record(subject, func(d time.Duration) {
+				now = now.Add(d)
+			})
+			g.Expect(subject.ShouldTrip()).Should(Equal(dt.expected))
+		})
+	}
+}
+
+func TestFindLatencyPercentiles(t *testing.T) {
+	g := NewWithT(t)
+	subject := tripping.Either(neverTrips, tripping.LatencyPercentile(tripping.LatencyPercentileOpts{
+		Budget:   time.Second,
+		Reported: []float64{0.5, 0.99},
+	}))
+	succeedAfter(subject, 100*time.Millisecond, 98)
+	succeedAfter(subject, 2*time.Second, 2)
+
+	percentiles, ok := tripping.FindLatencyPercentiles(subject)
+	g.Expect(ok).Should(BeTrue())
+	g.Expect(percentiles).Should(HaveLen(2))
+	g.Expect(percentiles[0].Quantile).Should(Equal(0.5))
+	g.Expect(percentiles[0].Latency).Should(BeNumerically("~", 100*time.Millisecond, time.Millisecond))
+	g.Expect(percentiles[1].Quantile).Should(Equal(0.99))
+	g.Expect(percentiles[1].Latency).Should(BeNumerically("~", 2*time.Second, 20*time.Millisecond))
+}
+
+func TestLatencyPercentile_Explain(t *testing.T) {
+	g := NewWithT(t)
+	subject := tripping.LatencyPercentile(tripping.LatencyPercentileOpts{
+		Percentile: 0.999,
+		Budget:     time.Second,
+	})
+	succeedAfter(subject, 10*time.Millisecond, 10)
+	g.Expect(tripping.Explain(subject).String()).Should(Equal(
+		"LatencyPercentile: ok (p99.9 10ms over 10 calls, budget 1s)"))
+}
@@ -0,0 +1,11 @@
+//go:generate go-enum --file=$GOFILE -noprefix
+
+package tripping
+
+// Policy is what a Classifier does with an error
+/* ENUM(
+Ignore,
+Record
+)
+*/
+type Policy uint8
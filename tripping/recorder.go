@@ -0,0 +1,130 @@
+package tripping
+
+import (
+	"errors"
+	"fmt"
+	"github.com/wojnosystems/go-rate-limit/rateLimit"
+	"time"
+)
+
+// ErrRecorderTripped is the breaker's last error when its Recorder trips it after a call that did not fail, such as
+// when calls are too slow. Use the breaker's TripExplanation to find out why
+var ErrRecorderTripped = errors.New("tripped by the breaker's Recorder after a successful call")
+
+// Recorder observes the outcome of every call a breaker attempts and decides when the breaker should trip.
+// Unlike a Decider, it learns about successes and is told each time the breaker transitions, so it can forget what
+// happened before an outage. Breakers call Recorders while holding their lock, so Recorders do not need to be
+// thread-safe, but each Recorder must only be used by a single breaker.
+type Recorder interface {
+	// OnSuccess is called when a call completes without a tripping error
+	OnSuccess(latency time.Duration)
+
+	// OnFailure is called when a call returns a tripping error
+	OnFailure(trippingErr *Error, latency time.Duration)
+
+	// ShouldTrip is called after each call while the breaker is closed, return true to trip the breaker
+	ShouldTrip() bool
+
+	// Reset is called each time the breaker transitions into state
+	Reset(state State)
+}
+
+// Recorder adapts the Decider to the Recorder interface. The Decider is consulted on each failure while the breaker
+// is Closed, like breakers did before Recorders existed, so failures while Open or HalfOpen do not use up a token
+// bucket Decider's tokens
+func (t Decider) Recorder() Recorder {
+	return RecorderFor(&t)
+}
+
+// RecorderFor works exactly like Decider.Recorder, but consults whichever Decider decider points to at the time of
+// each failure, so breakers can adapt their TripDecider while still allowing it to be replaced after they are created
+func RecorderFor(decider *Decider) Recorder {
+	return &deciderRecorder{
+		decider: decider,
+	}
+}
+
+// deciderRecorder is the Recorder created by Decider.Recorder and RecorderFor
+type deciderRecorder struct {
+	decider    *Decider
+	state      State
+	shouldTrip bool
+}
+
+// OnSuccess forgets what the Decider decided about the last failure, the Decider only decides on failures
+func (d *deciderRecorder) OnSuccess(_ time.Duration) {
+	d.shouldTrip = false
+}
+
+// OnFailure consults the Decider while the breaker is Closed
+func (d *deciderRecorder) OnFailure(trippingErr *Error, _ time.Duration) {
+	if d.state != Closed {
+		return
+	}
+	d.shouldTrip = (*d.decider).ShouldTrip(trippingErr)
+}
+
+// ShouldTrip returns what the Decider decided about the last failure
+func (d *deciderRecorder) ShouldTrip() bool {
+	return d.shouldTrip
+}
+
+// Reset remembers the state the breaker is in, the Decider has no state this can reset
+func (d *deciderRecorder) Reset(state State) {
+	d.state = state
+	d.shouldTrip = false
+}
+
+// Explain reports what the Decider decided about the last failure
+func (d *deciderRecorder) Explain() Explanation {
+	return Explanation{Name: "Decider", Tripped: d.shouldTrip}
+}
+
+// NewTokenBucketRecorder trips the breaker once the failures' costs exceed what the token bucket allows.
+// The bucket is replaced with a new one each time the breaker closes, so failures from before an outage do not count
+// against the recovered breaker.
+func NewTokenBucketRecorder(tokenBucketOpts rateLimit.TokenBucketOpts) Recorder {
+	return &tokenBucketRecorder{
+		opts:   tokenBucketOpts,
+		bucket: rateLimit.NewTokenBucket(tokenBucketOpts),
+	}
+}
+
+// tokenBucketRecorder is the Recorder created by NewTokenBucketRecorder
+type tokenBucketRecorder struct {
+	opts       rateLimit.TokenBucketOpts
+	bucket     *rateLimit.TokenBucket
+	shouldTrip bool
+}
+
+// OnSuccess does not refill the bucket, only time does
+func (t *tokenBucketRecorder) OnSuccess(_ time.Duration) {
+	t.shouldTrip = false
+}
+
+// OnFailure takes the failure's cost from the bucket
+func (t *tokenBucketRecorder) OnFailure(trippingErr *Error, _ time.Duration) {
+	t.shouldTrip = !t.bucket.Allowed(trippingErr.Cost)
+}
+
+// ShouldTrip is true if the bucket could not afford the last failure
+func (t *tokenBucketRecorder) ShouldTrip() bool {
+	return t.shouldTrip
+}
+
+// Reset starts with a new bucket when the breaker closes
+func (t *tokenBucketRecorder) Reset(state State) {
+	t.shouldTrip = false
+	if state == Closed {
+		t.bucket = rateLimit.NewTokenBucket(t.opts)
+	}
+}
+
+// Explain reports how many tokens are left
+func (t *tokenBucketRecorder) Explain() Explanation {
+	return Explanation{
+		Name:    "TokenBucket",
+		Tripped: t.shouldTrip,
+		Detail:  fmt.Sprintf("%d tokens left", t.bucket.Tokens()),
+	}
+}
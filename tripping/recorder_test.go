@@ -0,0 +1,123 @@
+package tripping
+
+import (
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-rate-limit/rateLimit"
+	"testing"
+	"time"
+)
+
+func TestDecider_Recorder(t *testing.T) {
+	cases := map[string]struct {
+		decider  Decider
+		record   func(recorder Recorder)
+		expected bool
+	}{
+		"default trips on failure": {
+			record: func(recorder Recorder) {
+				recorder.OnFailure(New(wrappedError), time.Second)
+			},
+			expected: true,
+		},
+		"decider does not trip": {
+			decider: func(_ *Error) (shouldTrip bool) {
+				return false
+			},
+			record: func(recorder Recorder) {
+				recorder.OnFailure(New(wrappedError), time.Second)
+			},
+		},
+		"success does not trip": {
+			record: func(recorder Recorder) {
+				recorder.OnFailure(New(wrappedError), time.Second)
+				recorder.OnSuccess(time.Second)
+			},
+		},
+		"reset does not trip": {
+			record: func(recorder Recorder) {
+				recorder.OnFailure(New(wrappedError), time.Second)
+				recorder.Reset(Closed)
+			},
+		},
+		"not consulted while half-open": {
+			record: func(recorder Recorder) {
+				recorder.Reset(HalfOpen)
+				recorder.OnFailure(New(wrappedError), time.Second)
+			},
+		},
+		"consulted again once closed": {
+			record: func(recorder Recorder) {
+				recorder.Reset(HalfOpen)
+				recorder.Reset(Closed)
+				recorder.OnFailure(New(wrappedError), time.Second)
+			},
+			expected: true,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			subject := dt.decider.Recorder()
+			dt.record(subject)
+			g.Expect(subject.ShouldTrip()).Should(Equal(dt.expected))
+		})
+	}
+}
+
+func TestRecorderFor(t *testing.T) {
+	g := NewWithT(t)
+	var decider Decider
+	subject := RecorderFor(&decider)
+	decider = func(_ *Error) (shouldTrip bool) {
+		return false
+	}
+	subject.OnFailure(New(wrappedError), time.Second)
+	g.Expect(subject.ShouldTrip()).Should(BeFalse())
+}
+
+func TestNewTokenBucketRecorder(t *testing.T) {
+	cases := map[string]struct {
+		record   func(recorder Recorder)
+		expected bool
+	}{
+		"within the bucket": {
+			record: func(recorder Recorder) {
+				recorder.OnFailure(NewWithCost(wrappedError, 2), time.Second)
+			},
+		},
+		"exceeds the bucket": {
+			record: func(recorder Recorder) {
+				recorder.OnFailure(NewWithCost(wrappedError, 2), time.Second)
+				recorder.OnFailure(New(wrappedError), time.Second)
+			},
+			expected: true,
+		},
+		"refilled when closed": {
+			record: func(recorder Recorder) {
+				recorder.OnFailure(NewWithCost(wrappedError, 2), time.Second)
+				recorder.Reset(Open)
+				recorder.Reset(Closed)
+				recorder.OnFailure(NewWithCost(wrappedError, 2), time.Second)
+			},
+		},
+		"not refilled when opened": {
+			record: func(recorder Recorder) {
+				recorder.OnFailure(NewWithCost(wrappedError, 2), time.Second)
+				recorder.Reset(Open)
+				recorder.OnFailure(New(wrappedError), time.Second)
+			},
+			expected: true,
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			subject := NewTokenBucketRecorder(rateLimit.TokenBucketOpts{
+				Capacity:      2,
+				InitialTokens: 2,
+			})
+			dt.record(subject)
+			g.Expect(subject.ShouldTrip()).Should(Equal(dt.expected))
+		})
+	}
+}
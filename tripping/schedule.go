@@ -0,0 +1,198 @@
+package tripping
+
+import (
+	"fmt"
+	"time"
+)
+
+const defaultRegimeName = "Otherwise"
+
+// Period repeats every week, from Start until End after midnight on each of the Weekdays, such as 9:00 to 17:00 on
+// weekdays. An End before the Start wraps past midnight, such as 22:00 to 6:00, and an End equal to the Start covers
+// the whole day
+type Period struct {
+	// Weekdays the Period starts on, leave empty for every day
+	Weekdays []time.Weekday
+
+	// Start and End are how long after midnight the Period starts and ends
+	Start time.Duration
+	End   time.Duration
+
+	// Location the Period is in, such as the business' timezone. Leave nil to use the breaker clock's location
+	Location *time.Location
+}
+
+// Contains is true if t is within the Period
+func (p Period) Contains(t time.Time) bool {
+	if p.Location != nil {
+		t = t.In(p.Location)
+	}
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	sinceMidnight := t.Sub(midnight)
+	switch {
+	case p.Start == p.End:
+		return p.startsOn(t.Weekday())
+	case p.Start < p.End:
+		return p.startsOn(t.Weekday()) && sinceMidnight >= p.Start && sinceMidnight < p.End
+	default:
+		// wraps past midnight, so the early hours belong to the previous day's Period
+		yesterday := (t.Weekday() + 6) % 7
+		return (p.startsOn(t.Weekday()) && sinceMidnight >= p.Start) || (p.startsOn(yesterday) && sinceMidnight < p.End)
+	}
+}
+
+// startsOn is true if the Period starts on the weekday
+func (p Period) startsOn(weekday time.Weekday) bool {
+	if len(p.Weekdays) == 0 {
+		return true
+	}
+	for _, day := range p.Weekdays {
+		if day == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// Regime is a Recorder, and optionally an OpenDuration, used during its Periods
+type Regime struct {
+	// Name of the Regime, such as "BusinessHours", is reported when the breaker switches to it
+	Name string
+
+	// Periods the Regime is active during
+	Periods []Period
+
+	// Recorder decides when to trip while the Regime is active
+	Recorder Recorder
+
+	// OpenDuration, if set, replaces the breaker's OpenDuration when it trips while the Regime is active
+	OpenDuration time.Duration
+}
+
+// active is true if now is in one of the Regime's Periods
+func (r Regime) active(now time.Time) bool {
+	for _, period := range r.Periods {
+		if period.Contains(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// Scheduler is implemented by Recorders that switch between Regimes, breakers report the active one in their
+// snapshots and emit an event each time they notice it switched
+type Scheduler interface {
+	ActiveRegime() Regime
+}
+
+// FindActiveRegime returns the active Regime of the first Scheduler in recorder's tree, see Walk
+func FindActiveRegime(recorder Recorder) (regime Regime, ok bool) {
+	Walk(recorder, func(recorder Recorder) {
+		if scheduler, isScheduler := recorder.(Scheduler); isScheduler && !ok {
+			regime, ok = scheduler.ActiveRegime(), true
+		}
+	})
+	return
+}
+
+// Schedule switches between Regimes by the time on the breaker's clock, such as to allow more failures during
+// business hours, when traffic is higher. The first Regime with a Period containing the current time is active,
+// otherwise is active when none are, and its Periods are ignored. It is named "Otherwise" unless given a Name.
+//
+// Every Regime's Recorder observes every call, so each is up to date when its Regime becomes active, but only the
+// active Regime decides whether to trip.
+//
+// Example:
+//
+//	businessHours := tripping.Period{
+//		Weekdays: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+//		Start:    9 * time.Hour,
+//		End:      17 * time.Hour,
+//	}
+//	tripping.Schedule(
+//		tripping.Regime{Recorder: tripping.ConsecutiveFailures(3)},
+//		tripping.Regime{Name: "BusinessHours", Periods: []tripping.Period{businessHours}, Recorder: tripping.ConsecutiveFailures(20)},
+//	)
+func Schedule(otherwise Regime, regimes ...Regime) Recorder {
+	if otherwise.Name == "" {
+		otherwise.Name = defaultRegimeName
+	}
+	return &scheduleRecorder{
+		otherwise: otherwise,
+		regimes:   regimes,
+	}
+}
+
+// scheduleRecorder is the Recorder created by Schedule
+type scheduleRecorder struct {
+	clock
+	otherwise Regime
+	regimes   []Regime
+}
+
+func (s *scheduleRecorder) OnSuccess(latency time.Duration) {
+	s.each(func(regime Regime) {
+		regime.Recorder.OnSuccess(latency)
+	})
+}
+
+func (s *scheduleRecorder) OnFailure(trippingErr *Error, latency time.Duration) {
+	s.each(func(regime Regime) {
+		regime.Recorder.OnFailure(trippingErr, latency)
+	})
+}
+
+func (s *scheduleRecorder) ShouldTrip() bool {
+	return s.ActiveRegime().Recorder.ShouldTrip()
+}
+
+func (s *scheduleRecorder) Reset(state State) {
+	s.each(func(regime Regime) {
+		regime.Recorder.Reset(state)
+	})
+}
+
+func (s *scheduleRecorder) ActiveRegime() Regime {
+	now := s.Now()
+	for _, regime := range s.regimes {
+		if regime.active(now) {
+			return regime
+		}
+	}
+	return s.otherwise
+}
+
+func (s *scheduleRecorder) Recorders() []Recorder {
+	var recorders []Recorder
+	s.each(func(regime Regime) {
+		recorders = append(recorders, regime.Recorder)
+	})
+	return recorders
+}
+
+// each calls with otherwise, then each of the regimes in order
+func (s *scheduleRecorder) each(with func(regime Regime)) {
+	with(s.otherwise)
+	for _, regime := range s.regimes {
+		with(regime)
+	}
+}
+
+func (s *scheduleRecorder) Explain() Explanation {
+	active := s.ActiveRegime()
+	var children []Explanation
+	s.each(func(regime Regime) {
+		explanation := Explain(regime.Recorder)
+		children = append(children, Explanation{
+			Name:     regime.Name,
+			Tripped:  explanation.Tripped,
+			Children: []Explanation{explanation},
+		})
+	})
+	return Explanation{
+		Name:     "Schedule",
+		Tripped:  active.Recorder.ShouldTrip(),
+		Detail:   fmt.Sprintf("%s is active", active.Name),
+		Children: children,
+	}
+}
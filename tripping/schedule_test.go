@@ -0,0 +1,125 @@
+package tripping_test
+
+import (
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"testing"
+	"time"
+)
+
+var weekdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+func TestPeriod_Contains(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("timezone database is not available")
+	}
+	// 2021-01-01 is a Friday
+	friday := func(hour, minute int) time.Time {
+		return time.Date(2021, 1, 1, hour, minute, 0, 0, time.UTC)
+	}
+	cases := map[string]struct {
+		period   tripping.Period
+		t        time.Time
+		expected bool
+	}{
+		"within": {
+			period:   tripping.Period{Start: 9 * time.Hour, End: 17 * time.Hour},
+			t:        friday(12, 0),
+			expected: true,
+		},
+		"at the start": {
+			period:   tripping.Period{Start: 9 * time.Hour, End: 17 * time.Hour},
+			t:        friday(9, 0),
+			expected: true,
+		},
+		"at the end": {
+			period: tripping.Period{Start: 9 * time.Hour, End: 17 * time.Hour},
+			t:      friday(17, 0),
+		},
+		"before the start": {
+			period: tripping.Period{Start: 9 * time.Hour, End: 17 * time.Hour},
+			t:      friday(8, 59),
+		},
+		"on a weekday": {
+			period:   tripping.Period{Weekdays: weekdays, Start: 9 * time.Hour, End: 17 * time.Hour},
+			t:        friday(12, 0),
+			expected: true,
+		},
+		"on the weekend": {
+			period: tripping.Period{Weekdays: weekdays, Start: 9 * time.Hour, End: 17 * time.Hour},
+			t:      friday(12, 0).AddDate(0, 0, 1),
+		},
+		"whole day": {
+			period:   tripping.Period{Weekdays: []time.Weekday{time.Friday}},
+			t:        friday(23, 59),
+			expected: true,
+		},
+		"wraps past midnight before midnight": {
+			period:   tripping.Period{Weekdays: []time.Weekday{time.Friday}, Start: 22 * time.Hour, End: 6 * time.Hour},
+			t:        friday(23, 0),
+			expected: true,
+		},
+		"wraps past midnight after midnight": {
+			period:   tripping.Period{Weekdays: []time.Weekday{time.Friday}, Start: 22 * time.Hour, End: 6 * time.Hour},
+			t:        friday(23, 0).Add(2 * time.Hour),
+			expected: true,
+		},
+		"wraps past midnight belongs to the previous day": {
+			period: tripping.Period{Weekdays: []time.Weekday{time.Friday}, Start: 22 * time.Hour, End: 6 * time.Hour},
+			t:      friday(1, 0),
+		},
+		"in another location": {
+			period:   tripping.Period{Start: 9 * time.Hour, End: 17 * time.Hour, Location: newYork},
+			t:        friday(20, 0),
+			expected: true,
+		},
+		"outside another location": {
+			period: tripping.Period{Start: 9 * time.Hour, End: 17 * time.Hour, Location: newYork},
+			t:      friday(12, 0),
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(dt.period.Contains(dt.t)).Should(Equal(dt.expected))
+		})
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	g := NewWithT(t)
+	now := time.Date(2021, 1, 1, 8, 0, 0, 0, time.UTC)
+	businessHours := tripping.Period{Weekdays: weekdays, Start: 9 * time.Hour, End: 17 * time.Hour}
+	subject := tripping.Schedule(
+		tripping.Regime{Recorder: tripping.ConsecutiveFailures(2)},
+		tripping.Regime{Name: "BusinessHours", Periods: []tripping.Period{businessHours}, Recorder: tripping.ConsecutiveFailures(4), OpenDuration: time.Minute},
+	)
+	tripping.SetClock(subject, func() time.Time {
+		return now
+	})
+
+	regime, ok := tripping.FindActiveRegime(subject)
+	g.Expect(ok).Should(BeTrue())
+	g.Expect(regime.Name).Should(Equal("Otherwise"))
+	failTimes(subject, wrappedError, 3)
+	g.Expect(subject.ShouldTrip()).Should(BeTrue())
+
+	now = now.Add(2 * time.Hour)
+	regime, _ = tripping.FindActiveRegime(subject)
+	g.Expect(regime.Name).Should(Equal("BusinessHours"))
+	g.Expect(regime.OpenDuration).Should(Equal(time.Minute))
+	g.Expect(subject.ShouldTrip()).Should(BeFalse())
+	failTimes(subject, wrappedError, 1)
+	g.Expect(subject.ShouldTrip()).Should(BeTrue())
+
+	explanation := tripping.Explain(subject)
+	g.Expect(explanation.Detail).Should(Equal("BusinessHours is active"))
+	g.Expect(explanation.Children).Should(HaveLen(2))
+}
+
+func TestFindActiveRegime_NotScheduled(t *testing.T) {
+	g := NewWithT(t)
+	_, ok := tripping.FindActiveRegime(tripping.ConsecutiveFailures(1))
+	g.Expect(ok).Should(BeFalse())
+}
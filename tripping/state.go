@@ -0,0 +1,12 @@
+//go:generate go-enum --file=$GOFILE -noprefix
+
+package tripping
+
+// State a breaker is in, as told to a Recorder when the breaker transitions. Two-state breakers are never HalfOpen
+/* ENUM(
+Closed,
+Open,
+HalfOpen
+)
+*/
+type State uint8
@@ -0,0 +1,31 @@
+package tripping
+
+// Unrecorded wraps err so breakers return it to the caller without recording the call as a success or a failure,
+// such as for a hedged attempt cancelled because the other attempt won, which says nothing about the backend's health
+func Unrecorded(err error) error {
+	return &unrecordedError{err: err}
+}
+
+// unrecordedError is created by Unrecorded
+type unrecordedError struct {
+	err error
+}
+
+// Error returns the wrapped error's string, or a placeholder if there was no error
+func (u *unrecordedError) Error() string {
+	if u.err == nil {
+		return "unrecorded"
+	}
+	return u.err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (u *unrecordedError) Unwrap() error {
+	return u.err
+}
+
+// IsUnrecorded is true if err was created by Unrecorded. Errors wrapping it are recorded as usual
+func IsUnrecorded(err error) bool {
+	_, ok := err.(*unrecordedError)
+	return ok
+}
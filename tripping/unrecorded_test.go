@@ -0,0 +1,21 @@
+package tripping_test
+
+import (
+	"context"
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"testing"
+)
+
+func TestUnrecorded(t *testing.T) {
+	g := NewWithT(t)
+	classifier := tripping.NewClassifier().Otherwise(tripping.Record, 1)
+	subject := tripping.Unrecorded(context.Canceled)
+
+	g.Expect(tripping.IsUnrecorded(subject)).Should(BeTrue())
+	g.Expect(subject).Should(MatchError(context.Canceled))
+	g.Expect(classifier.Classify(subject)).Should(BeNil())
+	g.Expect(tripping.Strip(subject)).Should(Equal(context.Canceled))
+	g.Expect(tripping.Strip(tripping.Unrecorded(tripping.New(context.DeadlineExceeded)))).Should(Equal(context.DeadlineExceeded))
+	g.Expect(tripping.IsUnrecorded(context.Canceled)).Should(BeFalse())
+}
@@ -0,0 +1,16 @@
+package tripping
+
+// Combinator is implemented by Recorders that combine other Recorders, so Walk can find every Recorder in a tree
+type Combinator interface {
+	Recorders() []Recorder
+}
+
+// Walk calls visit with recorder, then with each Recorder it combines, depth first
+func Walk(recorder Recorder, visit func(recorder Recorder)) {
+	visit(recorder)
+	if combinator, ok := recorder.(Combinator); ok {
+		for _, child := range combinator.Recorders() {
+			Walk(child, visit)
+		}
+	}
+}
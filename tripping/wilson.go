@@ -0,0 +1,75 @@
+package tripping
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const defaultWilsonConfidence = 0.95
+
+// WilsonFailureRate trips once it is confident that more than threshold of calls are failing: when the lower bound
+// of the Wilson score interval of the failure rate over the last windowSize calls exceeds threshold.
+// confidence is how sure it must be, such as 0.95, and defaults to 0.95.
+//
+// With few calls the interval is wide, so 1 failure of 2 calls does not trip a 0.5 threshold, but as calls are made
+// the interval narrows towards the observed rate, so no minimum number of calls needs to be tuned
+func WilsonFailureRate(threshold, confidence float64, windowSize int) Recorder {
+	if confidence <= 0 || confidence >= 1 {
+		confidence = defaultWilsonConfidence
+	}
+	return &wilsonRecorder{
+		threshold:  threshold,
+		confidence: confidence,
+		z:          math.Sqrt2 * math.Erfinv(2*confidence-1),
+		window:     newOutcomeWindow(windowSize),
+	}
+}
+
+// wilsonRecorder is the Recorder created by WilsonFailureRate
+type wilsonRecorder struct {
+	threshold  float64
+	confidence float64
+
+	// z is the standard normal quantile of the one-sided confidence
+	z      float64
+	window outcomeWindow
+}
+
+func (w *wilsonRecorder) OnSuccess(_ time.Duration) {
+	w.window.record(false)
+}
+
+func (w *wilsonRecorder) OnFailure(_ *Error, _ time.Duration) {
+	w.window.record(true)
+}
+
+// lowerBound of the Wilson score interval of the failure rate
+func (w *wilsonRecorder) lowerBound() float64 {
+	n := float64(w.window.calls())
+	if n == 0 {
+		return 0
+	}
+	p := w.window.rate()
+	z2 := w.z * w.z
+	center := p + z2/(2*n)
+	margin := w.z * math.Sqrt(p*(1-p)/n+z2/(4*n*n))
+	return (center - margin) / (1 + z2/n)
+}
+
+func (w *wilsonRecorder) ShouldTrip() bool {
+	return w.lowerBound() > w.threshold
+}
+
+func (w *wilsonRecorder) Reset(_ State) {
+	w.window.reset()
+}
+
+func (w *wilsonRecorder) Explain() Explanation {
+	return Explanation{
+		Name:    "WilsonFailureRate",
+		Tripped: w.ShouldTrip(),
+		Detail: fmt.Sprintf("%s, at least %.0f%% with %.0f%% confidence, threshold %.0f%%",
+			&w.window, w.lowerBound()*100, w.confidence*100, w.threshold*100),
+	}
+}
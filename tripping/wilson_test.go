@@ -0,0 +1,84 @@
+package tripping_test
+
+import (
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"testing"
+)
+
+func TestWilsonFailureRate(t *testing.T) {
+	cases := map[string]struct {
+		confidence float64
+		record     func(recorder tripping.Recorder)
+		expected   bool
+	}{
+		"no calls": {
+			record: func(recorder tripping.Recorder) {},
+		},
+		"1 of 2 failed": {
+			record: func(recorder tripping.Recorder) {
+				succeedTimes(recorder, 1)
+				failTimes(recorder, wrappedError, 1)
+			},
+		},
+		"2 of 2 failed": {
+			record: func(recorder tripping.Recorder) {
+				failTimes(recorder, wrappedError, 2)
+			},
+		},
+		"3 of 3 failed": {
+			record: func(recorder tripping.Recorder) {
+				failTimes(recorder, wrappedError, 3)
+			},
+			expected: true,
+		},
+		"3 of 3 failed at higher confidence": {
+			confidence: 0.99,
+			record: func(recorder tripping.Recorder) {
+				failTimes(recorder, wrappedError, 3)
+			},
+		},
+		"12 of 20 failed": {
+			record: func(recorder tripping.Recorder) {
+				succeedTimes(recorder, 8)
+				failTimes(recorder, wrappedError, 12)
+			},
+		},
+		"15 of 20 failed": {
+			record: func(recorder tripping.Recorder) {
+				succeedTimes(recorder, 5)
+				failTimes(recorder, wrappedError, 15)
+			},
+			expected: true,
+		},
+		"old failures leave the window": {
+			record: func(recorder tripping.Recorder) {
+				failTimes(recorder, wrappedError, 15)
+				succeedTimes(recorder, 20)
+			},
+		},
+		"reset": {
+			record: func(recorder tripping.Recorder) {
+				failTimes(recorder, wrappedError, 3)
+				recorder.Reset(tripping.Closed)
+			},
+		},
+	}
+	for caseName, dt := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			g := NewWithT(t)
+			subject := tripping.WilsonFailureRate(0.5, dt.confidence, 20)
+			dt.record(subject)
+			g.Expect(subject.ShouldTrip()).Should(Equal(dt.expected))
+		})
+	}
+}
+
+func TestWilsonFailureRate_Explain(t *testing.T) {
+	g := NewWithT(t)
+	subject := tripping.WilsonFailureRate(0.5, 0.95, 20)
+	succeedTimes(subject, 5)
+	failTimes(subject, wrappedError, 15)
+	g.Expect(tripping.Explain(subject).String()).Should(Equal(
+		"WilsonFailureRate: tripped (15 of 20 calls failed, at least 57% with 95% confidence, threshold 50%)"))
+}
@@ -1,6 +1,7 @@
 package twoStateCircuit
 
 import (
+	"github.com/wojnosystems/go-circuit-breaker/shadow"
 	"github.com/wojnosystems/go-circuit-breaker/tripping"
 	"github.com/wojnosystems/go-circuit-breaker/twoStateCircuit/state"
 	"github.com/wojnosystems/go-time-factory/timeFactory"
@@ -12,6 +13,22 @@ type Opts struct {
 	// TripDecider is consulted each time a tripping error occurs.
 	TripDecider tripping.Decider
 
+	// Recorder, if set, is used instead of the TripDecider. It observes the outcome and latency of every call and is
+	// reset each time the breaker transitions
+	Recorder tripping.Recorder
+
+	// Classifier decides which errors returned by callbacks count against the breaker. Leave nil to only count
+	// tripping errors
+	Classifier *tripping.Classifier
+
+	// CategoryRecorders give each category of tripping.Error its own Recorder, such as to trip on 3 connection
+	// refusals in a row. Failures in other categories are told to Recorder, see tripping.ByCategory
+	CategoryRecorders map[tripping.Category]tripping.Recorder
+
+	// CostFunc, if set, computes the cost of each failure from its error, latency and category, replacing the cost it
+	// was created with. See tripping.LatencyCost and circuitHTTP.DefaultCost
+	CostFunc tripping.CostFunc
+
 	// OpenDuration is how long to stay in the OpenState before closing again
 	OpenDuration time.Duration
 
@@ -20,14 +37,32 @@ type Opts struct {
 	// Do NOT close this channel or a panic will occur
 	OnStateChange chan<- state.State
 
+	// OnEvent if set, will emit an Event each time the breaker transitions and when it notices the Recorder switched
+	// to another tripping.Regime. Do NOT close this channel or a panic will occur
+	OnEvent chan<- Event
+
+	// Shadow, if true, runs the breaker in shadow mode: it tracks its state and emits transitions exactly as usual, but
+	// never rejects a call. Calls it would have rejected are attempted, but their outcomes are not recorded, as they
+	// would never have been seen. Use Decisions to find out how many calls it would have rejected
+	Shadow bool
+
+	// Shadows see the outcome and latency of every call this breaker attempts, so a new configuration can be compared
+	// against the live one using shadow.Compare. Set Shadow on each of them. They are told during the call, so buffer
+	// or drain their OnStateChange and OnEvent channels
+	Shadows []shadow.Breaker
+
 	// nowFactory allows the current time to be simulated
 	nowFactory timeFactory.Now
 }
 
 type mutableState struct {
-	state         state.State
-	lastError     error
-	openExpiresAt time.Time
+	state           state.State
+	lastError       error
+	openExpiresAt   time.Time
+	tripExplanation tripping.Explanation
+
+	// regime is the name of the Recorder's active tripping.Regime when last noticed, empty if it is not scheduled
+	regime string
 }
 
 // Breaker is a live circuit breaker that only has 2 states: closed and open.
@@ -36,49 +71,111 @@ type Breaker struct {
 	opts Opts
 	mu   sync.RWMutex
 	mutableState
+
+	decisions shadow.Counter
+
+	// categoryFailures counts every recorded failure by category, guarded by mu
+	categoryFailures map[tripping.Category]uint64
 }
 
 func New(opts Opts) *Breaker {
-	return &Breaker{
+	b := &Breaker{
 		opts: opts,
 		mutableState: mutableState{
 			state: state.Closed,
 		},
+		categoryFailures: make(map[tripping.Category]uint64),
+	}
+	if b.opts.Recorder == nil {
+		// follows the TripDecider, so it can still be replaced after the breaker is created
+		b.opts.Recorder = tripping.RecorderFor(&b.opts.TripDecider)
+	}
+	if len(b.opts.CategoryRecorders) > 0 {
+		b.opts.Recorder = tripping.ByCategory(b.opts.Recorder, b.opts.CategoryRecorders)
 	}
+	tripping.SetClock(b.opts.Recorder, b.now)
+	if regime, ok := tripping.FindActiveRegime(b.opts.Recorder); ok {
+		b.regime = regime.Name
+	}
+	return b
+}
+
+// now is the current time according to the breaker's, possibly simulated, clock
+func (b *Breaker) now() time.Time {
+	return b.opts.nowFactory.Get()
 }
 
 // Use the breaker, if closed, attempt the callback, if open, return the last error
 // automatically transitions state if necessary
+// callback can return any error, but only tripping errors and the errors the Classifier records are counted when
+// deciding whether to trip the breaker. All other errors are returned without contributing to the breaker's limits.
 // callbacks can be called concurrently. Use will not block while the callback is being executed.
 // This does mean that sometimes, callbacks will be called while the breaker has already tripped.
 func (b *Breaker) Use(callback func() error) error {
-	{
-		stateCopy, now := b.copyCurrentState()
-		if stateCopy.state == state.Open {
-			if stateCopy.openExpiresAt.After(now) {
-				// still in the open state, not expired
-				return stateCopy.lastError
-			}
-
-			b.transitionToClosedIfShould()
-		}
+	admitted, rejectedErr := b.admit()
+	b.decisions.Record(admitted)
+	if !admitted && !b.opts.Shadow {
+		return rejectedErr
 	}
 
-	// at this point, we have either returned or we're in the closed state
+	// at this point, we have either returned, we're in the closed state, or we're in shadow mode
+	start := b.opts.nowFactory.Get()
 	err := callback()
-	if !tripping.IsTripping(err) {
+	return b.record(admitted, err, b.opts.nowFactory.Get().Sub(start))
+}
+
+// Record works exactly like Use, but for a call that was already made, such as when this breaker is the shadow of
+// another, see shadow.Forward
+func (b *Breaker) Record(outcome error, latency time.Duration) {
+	admitted, _ := b.admit()
+	b.decisions.Record(admitted)
+	if !admitted && !b.opts.Shadow {
+		return
+	}
+	_ = b.record(admitted, outcome, latency)
+}
+
+// record the outcome of an attempted call and return the error Use should return
+func (b *Breaker) record(admitted bool, err error, latency time.Duration) error {
+	if tripping.IsUnrecorded(err) {
+		// says nothing about the health of the dependency, so neither the breaker nor its shadows see it
+		return tripping.Strip(err)
+	}
+	shadow.Forward(b.opts.Shadows, err, latency)
+	trippingError := b.opts.Classifier.Classify(err)
+	if trippingError == nil {
+		if admitted {
+			b.recordSuccess(latency)
+		}
 		// error was nil or not tripping, just return
 		return err
 	}
 
-	trippingError := err.(*tripping.Error)
-	unwrappedError := err.(*tripping.Error).Err
+	unwrappedError := tripping.Strip(err)
+	if !admitted {
+		// shadow mode, the breaker would have rejected this call so would never have seen the error
+		return unwrappedError
+	}
 
 	// we encountered an error, we need to count this against our error threshold and transition if need be
-	b.recordErrorAndTransitionToOpenIfShould(trippingError)
+	b.recordErrorAndTransitionToOpenIfShould(b.opts.CostFunc.Weigh(trippingError, latency), latency)
 	return unwrappedError
 }
 
+// admit returns true if the call should be attempted, otherwise it returns the error to reject it with
+func (b *Breaker) admit() (admitted bool, rejectedErr error) {
+	stateCopy, now := b.copyCurrentState()
+	if stateCopy.state == state.Open {
+		if stateCopy.openExpiresAt.After(now) {
+			// still in the open state, not expired
+			return false, stateCopy.lastError
+		}
+
+		b.transitionToClosedIfShould()
+	}
+	return true, nil
+}
+
 func (b *Breaker) copyCurrentState() (currentState mutableState, now time.Time) {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
@@ -102,13 +199,33 @@ func (b *Breaker) transitionToClosedIfShould() {
 	if b.state == state.Open && b.opts.nowFactory.Get().After(b.openExpiresAt) {
 		// perform the transition exactly once for this round
 		b.state = state.Closed
+		b.opts.Recorder.Reset(tripping.Closed)
+		event := b.newEvent()
 		afterUnlock = func() {
-			b.notifyStateChanged(state.Closed)
+			b.notifyStateChanged(event)
 		}
 	}
 }
 
-func (b *Breaker) recordErrorAndTransitionToOpenIfShould(trippingError *tripping.Error) {
+// recordSuccess tells the Recorder the call succeeded
+func (b *Breaker) recordSuccess(latency time.Duration) {
+	b.mu.Lock()
+	afterUnlock := doNothing
+	defer func() {
+		b.mu.Unlock()
+		afterUnlock()
+	}()
+
+	b.opts.Recorder.OnSuccess(latency)
+	afterUnlock = b.noticeRegimeSwitch()
+	if b.state != state.Closed || !b.opts.Recorder.ShouldTrip() {
+		return
+	}
+	// the Recorder trips on more than failures, such as latency
+	afterUnlock = both(afterUnlock, b.transitionToOpen(tripping.ErrRecorderTripped))
+}
+
+func (b *Breaker) recordErrorAndTransitionToOpenIfShould(trippingError *tripping.Error, latency time.Duration) {
 	b.mu.Lock()
 	afterUnlock := doNothing
 	defer func() {
@@ -117,7 +234,10 @@ func (b *Breaker) recordErrorAndTransitionToOpenIfShould(trippingError *tripping
 	}()
 
 	// record the error
-	errorRateWithinLimits := !b.opts.TripDecider.ShouldTrip(trippingError)
+	b.categoryFailures[trippingError.Category]++
+	b.opts.Recorder.OnFailure(trippingError, latency)
+	afterUnlock = b.noticeRegimeSwitch()
+	errorRateWithinLimits := !b.opts.Recorder.ShouldTrip()
 
 	if b.state != state.Closed || errorRateWithinLimits {
 		// already transitioned state to open OR
@@ -125,17 +245,70 @@ func (b *Breaker) recordErrorAndTransitionToOpenIfShould(trippingError *tripping
 		return
 	}
 
-	// transition to the Open State
-	b.lastError = trippingError.Err
+	afterUnlock = both(afterUnlock, b.transitionToOpen(trippingError.Err))
+}
+
+// transitionToOpen trips the breaker, explaining why before the Recorder is reset.
+// Must hold the lock, call the returned func after unlocking
+func (b *Breaker) transitionToOpen(lastError error) (afterUnlock func()) {
+	b.lastError = lastError
+	b.tripExplanation = tripping.Explain(b.opts.Recorder)
 	b.state = state.Open
-	b.openExpiresAt = b.opts.nowFactory.Get().Add(b.opts.OpenDuration)
-	afterUnlock = func() {
-		b.notifyStateChanged(state.Open)
+	b.openExpiresAt = b.opts.nowFactory.Get().Add(b.openDuration())
+	b.opts.Recorder.Reset(tripping.Open)
+	event := b.newEvent()
+	return func() {
+		b.notifyStateChanged(event)
 	}
 }
 
-func (b *Breaker) notifyStateChanged(newState state.State) {
+// notifyStateChanged will emit the new state if a OnStateChange listener was registered and the event if an OnEvent
+// listener was registered
+func (b *Breaker) notifyStateChanged(event Event) {
 	if b.opts.OnStateChange != nil {
-		b.opts.OnStateChange <- newState
+		b.opts.OnStateChange <- event.State
+	}
+	b.notifyEvent(event)
+}
+
+// notifyEvent will emit the event if an OnEvent listener was registered
+func (b *Breaker) notifyEvent(event Event) {
+	if b.opts.OnEvent != nil {
+		b.opts.OnEvent <- event
 	}
 }
+
+// CircuitState is the name of the state the breaker is currently in
+func (b *Breaker) CircuitState() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.state.String()
+}
+
+// RetryAfter is how long until the breaker will close and attempt requests again. Returns 0 if the breaker is closed
+// or the open state has already expired
+func (b *Breaker) RetryAfter() time.Duration {
+	stateCopy, now := b.copyCurrentState()
+	if stateCopy.state != state.Open || !stateCopy.openExpiresAt.After(now) {
+		return 0
+	}
+	return stateCopy.openExpiresAt.Sub(now)
+}
+
+// Decisions counts the calls the breaker admitted and rejected. In shadow mode, counts the calls it would have rejected
+func (b *Breaker) Decisions() shadow.Decisions {
+	return b.decisions.Decisions()
+}
+
+// TripExplanation describes why the breaker last tripped, see tripping.Explanation
+func (b *Breaker) TripExplanation() tripping.Explanation {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.tripExplanation
+}
+
+// Classify returns the tripping error the breaker records for err, or nil if err does not count against it, see
+// Opts.Classifier
+func (b *Breaker) Classify(err error) *tripping.Error {
+	return b.opts.Classifier.Classify(err)
+}
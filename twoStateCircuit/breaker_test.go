@@ -136,3 +136,52 @@ var _ = Describe("Breaker.Use", func() {
 		})
 	})
 })
+
+var _ = Describe("Breaker.RetryAfter", func() {
+	var (
+		subject *Breaker
+		now     time.Time
+	)
+	BeforeEach(func() {
+		now = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		subject = New(Opts{
+			OpenDuration: 30 * time.Second,
+			nowFactory: func() time.Time {
+				return now
+			},
+		})
+	})
+	When("closed", func() {
+		It("does not need to wait", func() {
+			Expect(subject.RetryAfter()).Should(Equal(time.Duration(0)))
+		})
+		It("describes the state", func() {
+			Expect(subject.CircuitState()).Should(Equal("Closed"))
+		})
+	})
+	When("open", func() {
+		BeforeEach(func() {
+			_ = subject.Use(func() error {
+				return trippingError
+			})
+			now = now.Add(10 * time.Second)
+		})
+		It("waits for the remainder of the open duration", func() {
+			Expect(subject.RetryAfter()).Should(Equal(20 * time.Second))
+		})
+		It("describes the state", func() {
+			Expect(subject.CircuitState()).Should(Equal("Open"))
+		})
+	})
+	When("open state expired", func() {
+		BeforeEach(func() {
+			_ = subject.Use(func() error {
+				return trippingError
+			})
+			now = now.Add(1 * time.Minute)
+		})
+		It("does not need to wait", func() {
+			Expect(subject.RetryAfter()).Should(Equal(time.Duration(0)))
+		})
+	})
+})
@@ -5,3 +5,7 @@ import "github.com/wojnosystems/go-circuit-breaker/tripping"
 func neverTrips(_ *tripping.Error) bool {
 	return false
 }
+
+func alwaysTrips(_ *tripping.Error) bool {
+	return true
+}
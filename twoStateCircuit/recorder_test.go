@@ -0,0 +1,99 @@
+package twoStateCircuit
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"time"
+)
+
+// recordingRecorder remembers what it was told and trips on every failure
+type recordingRecorder struct {
+	successes []time.Duration
+	failures  []time.Duration
+	resets    []tripping.State
+	failed    bool
+}
+
+func (r *recordingRecorder) OnSuccess(latency time.Duration) {
+	r.successes = append(r.successes, latency)
+	r.failed = false
+}
+
+func (r *recordingRecorder) OnFailure(_ *tripping.Error, latency time.Duration) {
+	r.failures = append(r.failures, latency)
+	r.failed = true
+}
+
+func (r *recordingRecorder) ShouldTrip() bool {
+	return r.failed
+}
+
+func (r *recordingRecorder) Reset(state tripping.State) {
+	r.resets = append(r.resets, state)
+}
+
+var _ = Describe("Breaker.Recorder", func() {
+	var (
+		subject  *Breaker
+		recorder *recordingRecorder
+		now      time.Time
+	)
+	BeforeEach(func() {
+		now = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		recorder = &recordingRecorder{}
+		subject = New(Opts{
+			TripDecider:  neverTrips,
+			Recorder:     recorder,
+			OpenDuration: time.Minute,
+			nowFactory: func() time.Time {
+				return now
+			},
+		})
+	})
+	It("records the latency of successes", func() {
+		_ = subject.Use(func() error {
+			now = now.Add(time.Second)
+			return nil
+		})
+		Expect(recorder.successes).Should(Equal([]time.Duration{time.Second}))
+	})
+	It("records the latency of failures instead of consulting the TripDecider", func() {
+		_ = subject.Use(func() error {
+			now = now.Add(2 * time.Second)
+			return trippingError
+		})
+		Expect(recorder.failures).Should(Equal([]time.Duration{2 * time.Second}))
+		Expect(subject.CircuitState()).Should(Equal("Open"))
+	})
+	It("resets on each transition", func() {
+		_ = subject.Use(func() error {
+			return trippingError
+		})
+		now = now.Add(2 * time.Minute)
+		_ = subject.Use(func() error {
+			return nil
+		})
+		Expect(recorder.resets).Should(Equal([]tripping.State{tripping.Open, tripping.Closed}))
+	})
+	It("explains why it tripped", func() {
+		subject = New(Opts{
+			TripDecider:  neverTrips,
+			Recorder:     tripping.ConsecutiveFailures(2),
+			OpenDuration: time.Minute,
+			nowFactory: func() time.Time {
+				return now
+			},
+		})
+		for i := 0; i < 2; i++ {
+			_ = subject.Use(func() error {
+				return trippingError
+			})
+		}
+		Expect(subject.TripExplanation()).Should(Equal(tripping.Explanation{
+			Name:    "ConsecutiveFailures",
+			Tripped: true,
+			Detail:  "2 of 2 failures in a row",
+		}))
+	})
+})
@@ -0,0 +1,117 @@
+package twoStateCircuit
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wojnosystems/go-circuit-breaker/shadow"
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"github.com/wojnosystems/go-circuit-breaker/twoStateCircuit/state"
+	"time"
+)
+
+var _ = Describe("Breaker shadow mode", func() {
+	var (
+		subject     *Breaker
+		stateChange chan state.State
+		decided     int
+	)
+	BeforeEach(func() {
+		stateChange = make(chan state.State, 10)
+		decided = 0
+		subject = New(Opts{
+			TripDecider: func(_ *tripping.Error) (shouldTrip bool) {
+				decided++
+				return true
+			},
+			OpenDuration:  time.Hour,
+			OnStateChange: stateChange,
+			Shadow:        true,
+		})
+		_ = subject.Use(func() error {
+			return trippingError
+		})
+	})
+	It("transitions as usual", func() {
+		Expect(stateChange).Should(Receive(Equal(state.Open)))
+	})
+	It("never rejects", func() {
+		called := false
+		err := subject.Use(func() error {
+			called = true
+			return nil
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(called).Should(BeTrue())
+	})
+	It("does not record outcomes of calls it would have rejected", func() {
+		err := subject.Use(func() error {
+			return trippingError
+		})
+		Expect(err).Should(Equal(trippingError.Err))
+		Expect(decided).Should(Equal(1))
+	})
+	It("counts the calls it would have rejected", func() {
+		_ = subject.Use(func() error {
+			return nil
+		})
+		Expect(subject.Decisions()).Should(Equal(shadow.Decisions{Admitted: 1, Rejected: 1}))
+	})
+})
+
+var _ = Describe("Breaker shadows", func() {
+	var (
+		live, tighter *Breaker
+	)
+	BeforeEach(func() {
+		tighter = New(Opts{
+			TripDecider:  alwaysTrips,
+			OpenDuration: time.Hour,
+			Shadow:       true,
+		})
+		live = New(Opts{
+			TripDecider:  neverTrips,
+			OpenDuration: time.Hour,
+			Shadows:      []shadow.Breaker{tighter},
+		})
+		for i := 0; i < 3; i++ {
+			_ = live.Use(func() error {
+				return trippingError
+			})
+		}
+	})
+	It("forwards the outcomes", func() {
+		Expect(tighter.CircuitState()).Should(Equal("Open"))
+	})
+	It("compares the decisions", func() {
+		report := shadow.Compare(live, map[string]shadow.Reporter{"tighter": tighter})
+		Expect(report.Live).Should(Equal(shadow.Decisions{Admitted: 3}))
+		Expect(report.Shadows[0].Decisions).Should(Equal(shadow.Decisions{Admitted: 1, Rejected: 2}))
+	})
+	It("forwards the latencies", func() {
+		now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		slower := New(Opts{
+			Recorder: tripping.LatencyPercentile(tripping.LatencyPercentileOpts{
+				Percentile: 0.9,
+				Budget:     time.Second,
+				MinSamples: 3,
+			}),
+			OpenDuration: time.Hour,
+			Shadow:       true,
+		})
+		live = New(Opts{
+			TripDecider:  neverTrips,
+			OpenDuration: time.Hour,
+			Shadows:      []shadow.Breaker{slower},
+			nowFactory: func() time.Time {
+				return now
+			},
+		})
+		for i := 0; i < 3; i++ {
+			_ = live.Use(func() error {
+				now = now.Add(2 * time.Second)
+				return nil
+			})
+		}
+		Expect(slower.CircuitState()).Should(Equal("Open"))
+	})
+})
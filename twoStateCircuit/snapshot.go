@@ -0,0 +1,76 @@
+package twoStateCircuit
+
+import (
+	"github.com/wojnosystems/go-circuit-breaker/tripping"
+	"github.com/wojnosystems/go-circuit-breaker/twoStateCircuit/state"
+	"time"
+)
+
+// Event is emitted on Opts.OnEvent each time the breaker transitions and when it notices the Recorder switched to
+// another tripping.Regime
+type Event struct {
+	// State the breaker is in after the event
+	State state.State
+
+	// Regime is the name of the Recorder's active tripping.Regime, empty if the Recorder is not scheduled
+	Regime string
+
+	// At is when the event happened
+	At time.Time
+}
+
+// Snapshot is a copy of the breaker's state at a moment in time, use it for metrics and debugging
+type Snapshot struct {
+	State         state.State
+	LastError     error
+	OpenExpiresAt time.Time
+
+	// TripExplanation describes why the breaker last tripped
+	TripExplanation tripping.Explanation
+
+	// CategoryFailures counts every failure recorded since the breaker was created, by tripping.Category.
+	// Uncategorized failures are counted under the empty Category
+	CategoryFailures map[tripping.Category]uint64
+
+	// ErrorBudget of the Recorder, nil unless it has one, such as tripping.BurnRate
+	ErrorBudget *tripping.ErrorBudget
+
+	// LatencyPercentiles of the Recorder, nil unless it tracks them, such as tripping.LatencyPercentile
+	LatencyPercentiles []tripping.Percentile
+
+	// Regime is the name of the Recorder's active tripping.Regime when the breaker last noticed, empty if the Recorder
+	// is not scheduled
+	Regime string
+}
+
+// Snapshot copies the breaker's current state
+func (b *Breaker) Snapshot() Snapshot {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	categoryFailures := make(map[tripping.Category]uint64, len(b.categoryFailures))
+	for category, failures := range b.categoryFailures {
+		categoryFailures[category] = failures
+	}
+	snapshot := Snapshot{
+		State:            b.state,
+		LastError:        b.lastError,
+		OpenExpiresAt:    b.openExpiresAt,
+		TripExplanation:  b.tripExplanation,
+		CategoryFailures: categoryFailures,
+		Regime:           b.regime,
+	}
+	if errorBudget, ok := tripping.FindErrorBudget(b.opts.Recorder); ok {
+		snapshot.ErrorBudget = &errorBudget
+	}
+	snapshot.LatencyPercentiles, _ = tripping.FindLatencyPercentiles(b.opts.Recorder)
+	return snapshot
+}
+
+// newEvent describes the breaker's current state as an Event. Must hold the lock
+func (b *Breaker) newEvent() Event {
+	return Event{
+		State:  b.state,
+		Regime: b.regime,
+		At:     b.opts.nowFactory.Get(),
+	}
+}